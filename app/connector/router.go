@@ -1,6 +1,7 @@
 package connector
 
 import (
+	"errors"
 	"net/http"
 	"time"
 
@@ -13,25 +14,30 @@ import (
 	hubspotDelivery "tiktok_api/hubspot/delivery"
 	hubspotMiddleware "tiktok_api/hubspot/delivery/http/middleware"
 
+	tiktokDelivery "tiktok_api/tiktok/delivery"
 	youtubeDelivery "tiktok_api/youtube/delivery"
 
 	"github.com/go-chi/httprate"
 )
 
+// - uploads stream hundreds of MB and are mounted outside of this timeout
+const requestTimeout = 60 * time.Second
+
 type Handler func(w http.ResponseWriter, r *http.Request) error
 
 func (h Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("content-type", "application/json")
 	err := h(w, r)
 	if err != nil {
-		switch e := err.(type) {
-		case httpErrors.Error:
+		//- http errors may come wrapped with context by the handler
+		var e httpErrors.Error
+		if errors.As(err, &e) {
 			w.WriteHeader(e.Status())
 			render.JSON(w, r, httpErrors.NewRestError(e.Status(), e.Error(), e.Causes()))
-		default:
-			w.WriteHeader(http.StatusInternalServerError)
-			render.JSON(w, r, httpErrors.NewRestError(http.StatusInternalServerError, e.Error(), httpErrors.ErrInternalServerError))
+			return
 		}
+		w.WriteHeader(http.StatusInternalServerError)
+		render.JSON(w, r, httpErrors.NewRestError(http.StatusInternalServerError, err.Error(), httpErrors.ErrInternalServerError))
 	}
 }
 
@@ -43,36 +49,115 @@ func SetupRouter() *chi.Mux {
 	r.Use(middleware.RealIP)
 	r.Use(middleware.URLFormat)
 	r.Use(middleware.Recoverer)
-	r.Use(middleware.Timeout(60 * time.Second))
-	r.Use(httprate.Limit(
-		10,
-		1*time.Minute,
-		httprate.WithLimitHandler(func(w http.ResponseWriter, r *http.Request) {
-			httpErrors.NewRestError(http.StatusTooManyRequests, "Too many requests", http.StatusTooManyRequests)
-		}),
-	)) //- 100 request per 1 minute
-
-	// Routing
-	// r.Route("/tiktok", tiktokHandler)
-	r.Route("/hubspot", hubspotHandler)
-	r.Route("/youtube", youtubeHandler)
+	r.Group(func(r chi.Router) {
+		r.Use(httprate.Limit(
+			10,
+			1*time.Minute,
+			httprate.WithLimitHandler(func(w http.ResponseWriter, r *http.Request) {
+				render.Status(r, http.StatusTooManyRequests)
+				render.JSON(w, r, httpErrors.NewTooManyRequestsError("Too many requests"))
+			}),
+		)) //- 100 request per 1 minute
+
+		// Routing
+		r.Route("/tiktok", tiktokHandler)
+		r.Route("/hubspot", hubspotHandler)
+		r.Route("/youtube", youtubeHandler)
+		r.Route("/jobs", jobsHandler)
+	})
+
+	//- tus clients send one request per chunk, so uploads are kept out of the rate limit and the request timeout
+	r.Route("/uploads", uploadsHandler)
 
 	return r
 }
 
 func youtubeHandler(r chi.Router) {
-	r.Method("GET", "/oauth", Handler(youtubeDelivery.GenerateAuthURL))
-	r.HandleFunc("/auth/callback", youtubeDelivery.OAuthYoutubeCallback)
+	//- uploads are not bound by the request timeout
+	r.Method("POST", "/video/file", Handler(youtubeDelivery.YoutubeVideoUploadFile))
 
 	r.Group(func(r chi.Router) {
+		r.Use(middleware.Timeout(requestTimeout))
+		r.Method("GET", "/oauth", Handler(youtubeDelivery.GenerateAuthURL))
+		r.Method("GET", "/quota", Handler(youtubeDelivery.YoutubeQuotaUsage))
+		r.HandleFunc("/auth/callback", youtubeDelivery.OAuthYoutubeCallback)
+
 		// r.Use(youtubeMiddleware.IsTokensValid)
 		// r.Method("POST", "/video/path", Handler(youtubeDelivery.YoutubeVideoUpload))
-		r.Method("POST", "/video/file", Handler(youtubeDelivery.YoutubeVideoUploadFile))
+		r.Method("POST", "/video/engagement/batch", Handler(youtubeDelivery.YoutubeVideoEngagementBatch))
 		r.Method("GET", "/video/engagement/{clientKey}/{videoId}", Handler(youtubeDelivery.YoutubeVideoEngagement))
+		r.Method("GET", "/video/engagement/{clientKey}/{videoId}/history", Handler(youtubeDelivery.YoutubeVideoEngagementHistory))
+		r.Method("GET", "/engagement/polling/{clientKey}", Handler(youtubeDelivery.YoutubeEngagementPollSettings))
+		r.Method("PUT", "/engagement/polling/{clientKey}", Handler(youtubeDelivery.YoutubeEngagementPollSettingsUpdate))
+		r.Method("POST", "/video/{videoId}/thumbnail", Handler(youtubeDelivery.YoutubeVideoSetThumbnail))
+		r.Method("PATCH", "/video/{clientKey}/{videoId}", Handler(youtubeDelivery.YoutubeVideoUpdate))
+		r.Method("DELETE", "/video/{clientKey}/{videoId}", Handler(youtubeDelivery.YoutubeVideoDelete))
+
+		r.Method("POST", "/playlist/{clientKey}", Handler(youtubeDelivery.YoutubePlaylistCreate))
+		r.Method("PATCH", "/playlist/{clientKey}/{playlistId}", Handler(youtubeDelivery.YoutubePlaylistUpdate))
+		r.Method("DELETE", "/playlist/{clientKey}/{playlistId}", Handler(youtubeDelivery.YoutubePlaylistDelete))
+		r.Method("GET", "/playlist/{clientKey}/{playlistId}/items", Handler(youtubeDelivery.YoutubePlaylistItemList))
+		r.Method("POST", "/playlist/{clientKey}/{playlistId}/items", Handler(youtubeDelivery.YoutubePlaylistItemAdd))
+		r.Method("PATCH", "/playlist/{clientKey}/{playlistId}/items/{itemId}", Handler(youtubeDelivery.YoutubePlaylistItemMove))
+		r.Method("DELETE", "/playlist/{clientKey}/{playlistId}/items/{itemId}", Handler(youtubeDelivery.YoutubePlaylistItemRemove))
+
+		r.Method("GET", "/caption/{clientKey}/{videoId}", Handler(youtubeDelivery.YoutubeCaptionList))
+		r.Method("POST", "/caption/{clientKey}/{videoId}", Handler(youtubeDelivery.YoutubeCaptionUpload))
+		r.Method("PUT", "/caption/{clientKey}/{videoId}/{captionId}", Handler(youtubeDelivery.YoutubeCaptionReplace))
+		r.Method("GET", "/caption/{clientKey}/{videoId}/{captionId}", Handler(youtubeDelivery.YoutubeCaptionDownload))
+		r.Method("DELETE", "/caption/{clientKey}/{videoId}/{captionId}", Handler(youtubeDelivery.YoutubeCaptionDelete))
+
+		r.Method("GET", "/channel/{clientKey}", Handler(youtubeDelivery.YoutubeChannelOverview))
+		r.Method("GET", "/channel/{clientKey}/uploads", Handler(youtubeDelivery.YoutubeChannelUploads))
+
+		r.Method("GET", "/comments/{clientKey}", Handler(youtubeDelivery.YoutubeCommentThreadList))
+		r.Method("POST", "/comments/{clientKey}/{commentId}/replies", Handler(youtubeDelivery.YoutubeCommentReply))
+		r.Method("PUT", "/comments/{clientKey}/{commentId}/moderation", Handler(youtubeDelivery.YoutubeCommentModerate))
+		r.Method("DELETE", "/comments/{clientKey}/{commentId}", Handler(youtubeDelivery.YoutubeCommentDelete))
+		r.Method("GET", "/video/schedule/{clientKey}", Handler(youtubeDelivery.YoutubeScheduledVideoList))
+		r.Method("PATCH", "/video/schedule/{clientKey}/{videoId}", Handler(youtubeDelivery.YoutubeVideoReschedule))
+		r.Method("DELETE", "/video/schedule/{clientKey}/{videoId}", Handler(youtubeDelivery.YoutubeVideoCancelSchedule))
+	})
+}
+
+func uploadsHandler(r chi.Router) {
+	//- EventSource cannot send the Tus-Resumable header, the stream stays open until the job finishes
+	r.Method("GET", "/{id}/events", Handler(youtubeDelivery.UploadJobEvents))
+
+	r.Group(func(r chi.Router) {
+		r.Use(youtubeDelivery.TusResumable)
+		r.Method("OPTIONS", "/", Handler(youtubeDelivery.TusOptions))
+		r.Method("POST", "/", Handler(youtubeDelivery.TusCreateUpload))
+		r.Method("HEAD", "/{id}", Handler(youtubeDelivery.TusUploadOffset))
+		r.Method("PATCH", "/{id}", Handler(youtubeDelivery.TusWriteChunk))
+		r.Method("DELETE", "/{id}", Handler(youtubeDelivery.TusTerminateUpload))
+	})
+}
+
+func jobsHandler(r chi.Router) {
+	r.Use(middleware.Timeout(requestTimeout))
+	r.Method("GET", "/{id}", Handler(youtubeDelivery.UploadJobStatus))
+}
+
+func tiktokHandler(r chi.Router) {
+	//- uploads are not bound by the request timeout, the publish status is polled before answering
+	r.Method("POST", "/video/file", Handler(tiktokDelivery.TiktokVideoUploadFile))
+
+	r.Group(func(r chi.Router) {
+		r.Use(middleware.Timeout(requestTimeout))
+		r.Method("GET", "/oauth", Handler(tiktokDelivery.GenerateAuthURL))
+		r.HandleFunc("/auth/callback", tiktokDelivery.OAuthTiktokCallback)
+		r.Method("POST", "/revoke", Handler(tiktokDelivery.RevokeAccess))
+
+		r.Method("GET", "/user/{clientKey}", Handler(tiktokDelivery.TiktokUserInfo))
+		r.Method("GET", "/video/publish/{clientKey}/{publishId}", Handler(tiktokDelivery.TiktokVideoPublishStatus))
+		r.Method("GET", "/video/list/{clientKey}", Handler(tiktokDelivery.TiktokVideoList))
+		r.Method("GET", "/video/engagement/{clientKey}/{videoId}", Handler(tiktokDelivery.TiktokVideoEngagement))
 	})
 }
 
 func hubspotHandler(r chi.Router) {
+	r.Use(middleware.Timeout(requestTimeout))
 
 	r.HandleFunc("/auth/callback", hubspotDelivery.OAuthHubspotCallback)
 	r.Method("POST", "/update", Handler(hubspotDelivery.UpdateToken))
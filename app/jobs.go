@@ -0,0 +1,95 @@
+package main
+
+import (
+	"time"
+
+	"tiktok_api/app/scheduler"
+	"tiktok_api/app/utils"
+	hubspotUsecase "tiktok_api/hubspot/usecase"
+	tiktokUsecase "tiktok_api/tiktok/usecase"
+	youtubeUsecase "tiktok_api/youtube/usecase"
+)
+
+// - TokenRefreshJobs refreshes stored tokens before they expire so background work never hits an expired token.
+// - The window has to be larger than the interval, otherwise a token can expire between two runs.
+func tokenRefreshJobs() []scheduler.Job {
+	interval := utils.DurationOrDefault("SCHEDULER.TOKEN_REFRESH_INTERVAL", 5*time.Minute)
+	window := utils.DurationOrDefault("SCHEDULER.TOKEN_REFRESH_WINDOW", 15*time.Minute)
+	if window < interval {
+		window = 2 * interval
+	}
+
+	return []scheduler.Job{
+		{
+			Name:     "youtube-token-refresh",
+			Interval: interval,
+			Run:      backfillFirst(youtubeUsecase.IndexExistingTokens, func() error { return youtubeUsecase.RefreshExpiringTokens(window) }),
+		},
+		{
+			Name:     "hubspot-token-refresh",
+			Interval: interval,
+			Run:      backfillFirst(hubspotUsecase.IndexExistingTokensUseCase, func() error { return hubspotUsecase.RefreshExpiringTokensUseCase(window) }),
+		},
+		{
+			Name:     "tiktok-token-refresh",
+			Interval: interval,
+			Run:      backfillFirst(tiktokUsecase.IndexExistingTokens, func() error { return tiktokUsecase.RefreshExpiringTokens(window) }),
+		},
+	}
+}
+
+// - engagementPollJobs keeps the cached engagement of uploaded videos fresh, videos uploaded before the poller
+// - existed are queued once on the first successful run.
+func engagementPollJobs() []scheduler.Job {
+	return []scheduler.Job{
+		{
+			Name:     "youtube-engagement-poll",
+			Interval: utils.DurationOrDefault("SCHEDULER.ENGAGEMENT_POLL_INTERVAL", time.Minute),
+			Run:      backfillFirst(youtubeUsecase.BackfillEngagementPolls, youtubeUsecase.PollDueEngagements),
+		},
+	}
+}
+
+// - uploadRequeueJobs puts uploads deferred for lack of youtube quota back in the queue once the quota is reset,
+// - and the uploads held by workers of a stopped instance once their heartbeat expired
+func uploadRequeueJobs() []scheduler.Job {
+	interval := utils.DurationOrDefault("SCHEDULER.UPLOAD_REQUEUE_INTERVAL", time.Minute)
+	return []scheduler.Job{
+		{
+			Name:     "youtube-upload-requeue",
+			Interval: interval,
+			Run:      youtubeUsecase.RequeueDeferredUploadJobs,
+		},
+		{
+			Name:     "youtube-upload-requeue-interrupted",
+			Interval: interval,
+			Run:      youtubeUsecase.RequeueInterruptedUploadJobs,
+		},
+	}
+}
+
+// - tusReapJobs removes unfinished tus uploads, with their bytes on disk, once their client stopped sending chunks
+func tusReapJobs() []scheduler.Job {
+	return []scheduler.Job{
+		{
+			Name:     "youtube-tus-reap",
+			Interval: utils.DurationOrDefault("SCHEDULER.TUS_REAP_INTERVAL", 10*time.Minute),
+			Run:      youtubeUsecase.ReapExpiredTusUploads,
+		},
+	}
+}
+
+// - backfillFirst makes run wait for one successful backfill, a failed backfill is tried again on the next tick.
+// - Jobs run one tick at a time so the flag needs no lock.
+func backfillFirst(backfill func() error, run func() error) func() error {
+	backfilled := false
+	return func() error {
+		if !backfilled {
+			if err := backfill(); err != nil {
+				return err
+			}
+			backfilled = true
+		}
+		return run()
+	}
+}
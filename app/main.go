@@ -1,11 +1,14 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"net/http"
 	"tiktok_api/app/config"
 	"tiktok_api/app/connector"
+	"tiktok_api/app/scheduler"
+	youtubeUsecase "tiktok_api/youtube/usecase"
 
 	"github.com/spf13/viper"
 
@@ -28,6 +31,14 @@ func main() {
 	//- logger initialize
 	log := logger.NewLogrusLogger()
 
+	//- background jobs
+	jobs := tokenRefreshJobs()
+	jobs = append(jobs, engagementPollJobs()...)
+	jobs = append(jobs, uploadRequeueJobs()...)
+	jobs = append(jobs, tusReapJobs()...)
+	scheduler.Start(context.Background(), jobs...)
+	youtubeUsecase.StartUploadWorkers(context.Background(), viper.GetInt("YOUTUBE.UPLOAD_WORKERS"))
+
 	//- go-chi implementation
 	r := connector.SetupRouter()
 
@@ -14,6 +14,8 @@ var (
 	ErrInternalServerError = errors.New("Internal Server Error")
 	ErrRequestTimeout      = errors.New("Request Timeout")
 	ErrPermissionDenied    = errors.New("Permission Denied")
+	ErrRequestTooLarge     = errors.New("Request Entity Too Large")
+	ErrTooManyRequests     = errors.New("Too Many Requests")
 )
 
 type Error interface {
@@ -85,6 +87,24 @@ func NewForbiddenError(causes interface{}) Error {
 	}
 }
 
+// New Request Entity Too Large Error
+func NewRequestTooLargeError(causes interface{}) Error {
+	return RestError{
+		ErrStatus: http.StatusRequestEntityTooLarge,
+		ErrError:  ErrRequestTooLarge.Error(),
+		ErrCauses: causes,
+	}
+}
+
+// New Too Many Requests Error
+func NewTooManyRequestsError(causes interface{}) Error {
+	return RestError{
+		ErrStatus: http.StatusTooManyRequests,
+		ErrError:  ErrTooManyRequests.Error(),
+		ErrCauses: causes,
+	}
+}
+
 // New Internal Server Error
 func NewInternalServerError(causes interface{}) Error {
 	result := RestError{
@@ -0,0 +1,55 @@
+package scheduler
+
+import (
+	"context"
+	"time"
+
+	"tiktok_api/app/logger"
+)
+
+var log = logger.NewLogrusLogger()
+
+// - Job is a background task run on a fixed interval
+type Job struct {
+	Name     string
+	Interval time.Duration
+	Run      func() error
+}
+
+// - Start runs every job once right away and then on its own interval until ctx is cancelled
+func Start(ctx context.Context, jobs ...Job) {
+	for _, job := range jobs {
+		go run(ctx, job)
+	}
+}
+
+func run(ctx context.Context, job Job) {
+	ticker := time.NewTicker(job.Interval)
+	defer ticker.Stop()
+
+	for {
+		runOnce(job)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// - runOnce keeps a panicking job from taking the whole server down
+func runOnce(job Job) {
+	fields := logger.Fields{
+		"service": "Scheduler",
+		"job":     job.Name,
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			log.Fields(fields).Warnf("Job panicked: %v", r)
+		}
+	}()
+
+	if err := job.Run(); err != nil {
+		log.Fields(fields).Errorf(err, "Job failed")
+	}
+}
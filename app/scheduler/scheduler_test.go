@@ -0,0 +1,39 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStart_RunsJobUntilCancelled(t *testing.T) {
+	var runs int32
+	ctx, cancel := context.WithCancel(context.Background())
+
+	Start(ctx, Job{
+		Name:     "counter",
+		Interval: 10 * time.Millisecond,
+		Run: func() error {
+			atomic.AddInt32(&runs, 1)
+			return nil
+		},
+	})
+
+	assert.Eventually(t, func() bool { return atomic.LoadInt32(&runs) >= 3 }, time.Second, 5*time.Millisecond)
+	cancel()
+	time.Sleep(30 * time.Millisecond)
+	stopped := atomic.LoadInt32(&runs)
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, stopped, atomic.LoadInt32(&runs))
+}
+
+func TestRunOnce_SurvivesFailingJobs(t *testing.T) {
+	assert.NotPanics(t, func() {
+		runOnce(Job{Name: "error", Run: func() error { return errors.New("boom") }})
+		runOnce(Job{Name: "panic", Run: func() error { panic("boom") }})
+	})
+}
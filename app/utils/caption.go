@@ -0,0 +1,171 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// - caption files are plain text, far below the 100MB youtube accepts
+const CaptionMaxSize = 10 << 20
+
+const (
+	CaptionFormatSRT    = "srt"
+	CaptionFormatWebVTT = "vtt"
+)
+
+var CaptionContentType = map[string]string{
+	CaptionFormatSRT:    "application/x-subrip",
+	CaptionFormatWebVTT: "text/vtt",
+}
+
+var ErrEmptyCaption = errors.New("caption file has no cues")
+
+var srtTimingPattern = regexp.MustCompile(`^(\d{2,}):(\d{2}):(\d{2}),(\d{3}) --> (\d{2,}):(\d{2}):(\d{2}),(\d{3})(\s.*)?$`)
+var vttTimingPattern = regexp.MustCompile(`^(?:(\d{2,}):)?(\d{2}):(\d{2})\.(\d{3})[ \t]+-->[ \t]+(?:(\d{2,}):)?(\d{2}):(\d{2})\.(\d{3})([ \t].*)?$`)
+
+// - ValidateCaption checks an SRT or WebVTT file and returns its format, WebVTT files are told apart by their header
+func ValidateCaption(data []byte) (string, error) {
+	if !utf8.Valid(data) {
+		return "", errors.New("caption file must be UTF-8 encoded")
+	}
+	text := strings.TrimPrefix(string(data), "\uFEFF")
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	lines := strings.Split(text, "\n")
+
+	if strings.HasPrefix(lines[0], "WEBVTT") {
+		return CaptionFormatWebVTT, validateWebVTT(lines)
+	}
+	return CaptionFormatSRT, validateSRT(lines)
+}
+
+// - SRT is a list of blocks: a counter, a timing line and at least one line of text
+func validateSRT(lines []string) error {
+	cues := 0
+	for i := 0; i < len(lines); i++ {
+		if strings.TrimSpace(lines[i]) == "" {
+			continue
+		}
+		if _, err := strconv.Atoi(strings.TrimSpace(lines[i])); err != nil {
+			return fmt.Errorf("line %d: expected a cue number", i+1)
+		}
+		i++
+		if i >= len(lines) {
+			return fmt.Errorf("line %d: expected a timing line", i+1)
+		}
+		match := srtTimingPattern.FindStringSubmatch(strings.TrimSpace(lines[i]))
+		if match == nil {
+			return fmt.Errorf("line %d: expected a timing line like 00:00:01,000 --> 00:00:02,000", i+1)
+		}
+		if err := checkCueTiming(match[1:5], match[5:9]); err != nil {
+			return fmt.Errorf("line %d: %w", i+1, err)
+		}
+		if i+1 >= len(lines) || strings.TrimSpace(lines[i+1]) == "" {
+			return fmt.Errorf("line %d: cue has no text", i+1)
+		}
+		for i+1 < len(lines) && strings.TrimSpace(lines[i+1]) != "" {
+			i++
+		}
+		cues++
+	}
+	if cues == 0 {
+		return ErrEmptyCaption
+	}
+	return nil
+}
+
+// - WebVTT starts with the header block, then cues with an optional identifier, NOTE, STYLE and REGION blocks are skipped
+func validateWebVTT(lines []string) error {
+	header := lines[0]
+	if header != "WEBVTT" && !strings.HasPrefix(header, "WEBVTT ") && !strings.HasPrefix(header, "WEBVTT\t") {
+		return errors.New("line 1: expected the WEBVTT header")
+	}
+
+	cues := 0
+	i := 1
+	//- the header block ends at the first blank line
+	for i < len(lines) && strings.TrimSpace(lines[i]) != "" {
+		i++
+	}
+	for ; i < len(lines); i++ {
+		if strings.TrimSpace(lines[i]) == "" {
+			continue
+		}
+		block := i
+		for i+1 < len(lines) && strings.TrimSpace(lines[i+1]) != "" {
+			i++
+		}
+		if isWebVTTMetaBlock(lines[block]) {
+			continue
+		}
+
+		timing := block
+		if !strings.Contains(lines[timing], "-->") {
+			//- cue identifier
+			timing++
+		}
+		if timing > i {
+			return fmt.Errorf("line %d: expected a timing line", timing+1)
+		}
+		match := vttTimingPattern.FindStringSubmatch(strings.TrimSpace(lines[timing]))
+		if match == nil {
+			return fmt.Errorf("line %d: expected a timing line like 00:00:01.000 --> 00:00:02.000", timing+1)
+		}
+		if err := checkCueTiming(match[1:5], match[5:9]); err != nil {
+			return fmt.Errorf("line %d: %w", timing+1, err)
+		}
+		cues++
+	}
+	if cues == 0 {
+		return ErrEmptyCaption
+	}
+	return nil
+}
+
+func isWebVTTMetaBlock(line string) bool {
+	for _, keyword := range []string{"NOTE", "STYLE", "REGION"} {
+		if line == keyword || strings.HasPrefix(line, keyword+" ") || strings.HasPrefix(line, keyword+"\t") {
+			return true
+		}
+	}
+	return false
+}
+
+// - checkCueTiming takes hours, minutes, seconds and milliseconds of both ends, hours may be empty in WebVTT
+func checkCueTiming(start []string, end []string) error {
+	startAt, err := cueTime(start)
+	if err != nil {
+		return err
+	}
+	endAt, err := cueTime(end)
+	if err != nil {
+		return err
+	}
+	if endAt < startAt {
+		return errors.New("cue ends before it starts")
+	}
+	return nil
+}
+
+func cueTime(parts []string) (time.Duration, error) {
+	var values [4]int
+	for i, part := range parts {
+		if part == "" {
+			continue
+		}
+		value, err := strconv.Atoi(part)
+		if err != nil {
+			return 0, err
+		}
+		values[i] = value
+	}
+	if values[1] > 59 || values[2] > 59 {
+		return 0, errors.New("minutes and seconds must be below 60")
+	}
+	return time.Duration(values[0])*time.Hour + time.Duration(values[1])*time.Minute +
+		time.Duration(values[2])*time.Second + time.Duration(values[3])*time.Millisecond, nil
+}
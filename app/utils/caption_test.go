@@ -0,0 +1,91 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateCaption(t *testing.T) {
+	tests := []struct {
+		name       string
+		caption    string
+		wantFormat string
+		wantErr    string
+	}{
+		{
+			name:       "srt",
+			caption:    "1\n00:00:01,000 --> 00:00:02,500\nHello\n\n2\n00:00:03,000 --> 00:00:04,000\nSecond\nline\n",
+			wantFormat: CaptionFormatSRT,
+		},
+		{
+			name:       "srt with bom and windows line endings",
+			caption:    "\uFEFF1\r\n00:00:01,000 --> 00:00:02,000\r\nHello\r\n",
+			wantFormat: CaptionFormatSRT,
+		},
+		{
+			name:       "srt with dot separator",
+			caption:    "1\n00:00:01.000 --> 00:00:02.000\nHello\n",
+			wantFormat: CaptionFormatSRT,
+			wantErr:    "line 2: expected a timing line like 00:00:01,000 --> 00:00:02,000",
+		},
+		{
+			name:       "srt cue without text",
+			caption:    "1\n00:00:01,000 --> 00:00:02,000\n\n",
+			wantFormat: CaptionFormatSRT,
+			wantErr:    "line 2: cue has no text",
+		},
+		{
+			name:       "srt cue ending before it starts",
+			caption:    "1\n00:00:03,000 --> 00:00:02,000\nHello\n",
+			wantFormat: CaptionFormatSRT,
+			wantErr:    "line 2: cue ends before it starts",
+		},
+		{
+			name:       "empty file",
+			caption:    "\n\n",
+			wantFormat: CaptionFormatSRT,
+			wantErr:    ErrEmptyCaption.Error(),
+		},
+		{
+			name:       "webvtt",
+			caption:    "WEBVTT - campaign\nKind: captions\n\nNOTE reviewed\nby legal\n\nintro\n00:01.000 --> 00:02.000 align:start\nHello\n\n00:00:03.000 --> 00:00:04.000\n<v Anna>Hi\n",
+			wantFormat: CaptionFormatWebVTT,
+		},
+		{
+			name:       "webvtt with bad timing",
+			caption:    "WEBVTT\n\n00:00:01,000 --> 00:00:02,000\nHello\n",
+			wantFormat: CaptionFormatWebVTT,
+			wantErr:    "line 3: expected a timing line like 00:00:01.000 --> 00:00:02.000",
+		},
+		{
+			name:       "webvtt without cues",
+			caption:    "WEBVTT\n\nNOTE nothing here\n",
+			wantFormat: CaptionFormatWebVTT,
+			wantErr:    ErrEmptyCaption.Error(),
+		},
+		{
+			name:       "webvtt bad header",
+			caption:    "WEBVTTX\n\n00:01.000 --> 00:02.000\nHello\n",
+			wantFormat: CaptionFormatWebVTT,
+			wantErr:    "line 1: expected the WEBVTT header",
+		},
+		{
+			name:    "not utf-8",
+			caption: "1\n00:00:01,000 --> 00:00:02,000\n\xff\xfe\n",
+			wantErr: "caption file must be UTF-8 encoded",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			format, err := ValidateCaption([]byte(test.caption))
+			if test.wantErr != "" {
+				assert.EqualError(t, err, test.wantErr)
+			} else {
+				assert.Nil(t, err)
+			}
+			assert.Equal(t, test.wantFormat, format)
+		})
+	}
+}
@@ -0,0 +1,15 @@
+package utils
+
+import (
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// - DurationOrDefault reads a duration from the config, fallback when it is missing or not positive
+func DurationOrDefault(key string, fallback time.Duration) time.Duration {
+	if d := viper.GetDuration(key); d > 0 {
+		return d
+	}
+	return fallback
+}
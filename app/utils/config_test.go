@@ -0,0 +1,21 @@
+package utils
+
+import (
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDurationOrDefault(t *testing.T) {
+	assert.Equal(t, time.Minute, DurationOrDefault("TEST.MISSING_INTERVAL", time.Minute))
+
+	viper.Set("TEST.INTERVAL", "90s")
+	defer viper.Set("TEST.INTERVAL", nil)
+	assert.Equal(t, 90*time.Second, DurationOrDefault("TEST.INTERVAL", time.Minute))
+
+	viper.Set("TEST.NEGATIVE_INTERVAL", "-1m")
+	defer viper.Set("TEST.NEGATIVE_INTERVAL", nil)
+	assert.Equal(t, time.Minute, DurationOrDefault("TEST.NEGATIVE_INTERVAL", time.Minute))
+}
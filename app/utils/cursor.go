@@ -0,0 +1,36 @@
+package utils
+
+import (
+	"encoding/base64"
+	"errors"
+	"strings"
+)
+
+// - cursors carry the page token of the upstream API, the prefix lets a changed format be told apart
+const cursorPrefix = "c1:"
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// - EncodeCursor hides an upstream page token behind an opaque cursor, an empty token gives an empty cursor
+func EncodeCursor(pageToken string) string {
+	if pageToken == "" {
+		return ""
+	}
+	return base64.RawURLEncoding.EncodeToString([]byte(cursorPrefix + pageToken))
+}
+
+// - DecodeCursor returns the page token of a cursor made by EncodeCursor, an empty cursor is the first page
+func DecodeCursor(cursor string) (string, error) {
+	if cursor == "" {
+		return "", nil
+	}
+	decoded, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return "", ErrInvalidCursor
+	}
+	pageToken, ok := strings.CutPrefix(string(decoded), cursorPrefix)
+	if !ok || pageToken == "" {
+		return "", ErrInvalidCursor
+	}
+	return pageToken, nil
+}
@@ -0,0 +1,27 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCursor(t *testing.T) {
+	cursor := EncodeCursor("CAUQAA")
+	assert.NotContains(t, cursor, "CAUQAA")
+
+	pageToken, err := DecodeCursor(cursor)
+	assert.Nil(t, err)
+	assert.Equal(t, "CAUQAA", pageToken)
+
+	assert.Equal(t, "", EncodeCursor(""))
+	pageToken, err = DecodeCursor("")
+	assert.Nil(t, err)
+	assert.Equal(t, "", pageToken)
+
+	_, err = DecodeCursor("CAUQAA")
+	assert.ErrorIs(t, err, ErrInvalidCursor)
+
+	_, err = DecodeCursor("not base64!")
+	assert.ErrorIs(t, err, ErrInvalidCursor)
+}
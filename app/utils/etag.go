@@ -0,0 +1,32 @@
+package utils
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+)
+
+// - ETag returns a strong entity tag of the JSON encoding of v, equal values always give the same tag
+func ETag(v interface{}) (string, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	sum := sha1.Sum(data)
+	return `"` + hex.EncodeToString(sum[:8]) + `"`, nil
+}
+
+// - ETagMatch tells whether an If-None-Match header lists the tag, weak tags match their strong counterpart
+func ETagMatch(ifNoneMatch string, etag string) bool {
+	if ifNoneMatch == "" || etag == "" {
+		return false
+	}
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,28 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestETag(t *testing.T) {
+	etag, err := ETag(map[string]uint64{"viewCount": 10})
+	assert.Nil(t, err)
+	assert.Regexp(t, `^"[0-9a-f]{16}"$`, etag)
+
+	same, _ := ETag(map[string]uint64{"viewCount": 10})
+	assert.Equal(t, etag, same)
+
+	other, _ := ETag(map[string]uint64{"viewCount": 11})
+	assert.NotEqual(t, etag, other)
+}
+
+func TestETagMatch(t *testing.T) {
+	etag := `"0123456789abcdef"`
+	assert.True(t, ETagMatch(`"0123456789abcdef"`, etag))
+	assert.True(t, ETagMatch(`"aaaa", W/"0123456789abcdef"`, etag))
+	assert.True(t, ETagMatch(`*`, etag))
+	assert.False(t, ETagMatch(`"aaaa"`, etag))
+	assert.False(t, ETagMatch("", etag))
+}
@@ -0,0 +1,27 @@
+package utils
+
+import (
+	"errors"
+	"net/http"
+
+	"golang.org/x/exp/slices"
+)
+
+// - youtube limit for a custom thumbnail
+const ThumbnailMaxSize = 2 << 20
+
+var ThumbnailContentType = []string{
+	"image/jpeg",
+	"image/png",
+}
+
+var ErrInvalidThumbnail = errors.New("thumbnail must be a JPEG or PNG image")
+
+// - DetectThumbnailType reads the type from the magic bytes, the Content-Type sent by the client is not trusted
+func DetectThumbnailType(data []byte) (string, error) {
+	contentType := http.DetectContentType(data)
+	if !slices.Contains(ThumbnailContentType, contentType) {
+		return "", ErrInvalidThumbnail
+	}
+	return contentType, nil
+}
@@ -0,0 +1,24 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDetectThumbnailType(t *testing.T) {
+	contentType, err := DetectThumbnailType([]byte("\xFF\xD8\xFF\xE0\x00\x10JFIF\x00"))
+	assert.Nil(t, err)
+	assert.Equal(t, "image/jpeg", contentType)
+
+	contentType, err = DetectThumbnailType([]byte("\x89PNG\x0D\x0A\x1A\x0A\x00\x00\x00\x0DIHDR"))
+	assert.Nil(t, err)
+	assert.Equal(t, "image/png", contentType)
+
+	//- the extension or Content-Type of a gif renamed to .jpg does not matter
+	_, err = DetectThumbnailType([]byte("GIF89a\x01\x00\x01\x00"))
+	assert.ErrorIs(t, err, ErrInvalidThumbnail)
+
+	_, err = DetectThumbnailType([]byte("not an image"))
+	assert.ErrorIs(t, err, ErrInvalidThumbnail)
+}
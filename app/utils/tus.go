@@ -0,0 +1,59 @@
+package utils
+
+import (
+	"encoding/base64"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// - ParseTusMetadata decodes an Upload-Metadata header: comma separated pairs of a key
+// - and an optional base64 value separated by a space
+func ParseTusMetadata(header string) (map[string]string, error) {
+	metadata := map[string]string{}
+	if strings.TrimSpace(header) == "" {
+		return metadata, nil
+	}
+
+	for _, pair := range strings.Split(header, ",") {
+		fields := strings.Fields(pair)
+		if len(fields) == 0 || len(fields) > 2 {
+			return nil, fmt.Errorf("invalid Upload-Metadata pair %q", strings.TrimSpace(pair))
+		}
+
+		key := fields[0]
+		if _, exist := metadata[key]; exist {
+			return nil, fmt.Errorf("duplicated Upload-Metadata key %q", key)
+		}
+		if len(fields) == 1 {
+			metadata[key] = ""
+			continue
+		}
+
+		value, err := base64.StdEncoding.DecodeString(fields[1])
+		if err != nil {
+			return nil, fmt.Errorf("Upload-Metadata value of %q is not base64", key)
+		}
+		metadata[key] = string(value)
+	}
+	return metadata, nil
+}
+
+// - EncodeTusMetadata is the reverse of ParseTusMetadata, keys are sorted to keep the header stable
+func EncodeTusMetadata(metadata map[string]string) string {
+	keys := make([]string, 0, len(metadata))
+	for key := range metadata {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, 0, len(keys))
+	for _, key := range keys {
+		if metadata[key] == "" {
+			pairs = append(pairs, key)
+			continue
+		}
+		pairs = append(pairs, key+" "+base64.StdEncoding.EncodeToString([]byte(metadata[key])))
+	}
+	return strings.Join(pairs, ",")
+}
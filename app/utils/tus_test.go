@@ -0,0 +1,37 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseTusMetadata(t *testing.T) {
+	metadata, err := ParseTusMetadata("filename bXkgdmlkZW8ubXA0,client_key YWJj, is_draft")
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]string{
+		"filename":   "my video.mp4",
+		"client_key": "abc",
+		"is_draft":   "",
+	}, metadata)
+
+	metadata, err = ParseTusMetadata("")
+	assert.Nil(t, err)
+	assert.Empty(t, metadata)
+
+	_, err = ParseTusMetadata("filename not-base64!")
+	assert.NotNil(t, err)
+
+	_, err = ParseTusMetadata("filename YQ==,filename Yg==")
+	assert.NotNil(t, err)
+}
+
+func TestEncodeTusMetadata(t *testing.T) {
+	metadata := map[string]string{"filename": "my video.mp4", "is_draft": "", "client_key": "abc"}
+	header := EncodeTusMetadata(metadata)
+	assert.Equal(t, "client_key YWJj,filename bXkgdmlkZW8ubXA0,is_draft", header)
+
+	parsed, err := ParseTusMetadata(header)
+	assert.Nil(t, err)
+	assert.Equal(t, metadata, parsed)
+}
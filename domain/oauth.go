@@ -24,6 +24,9 @@ type OAuth struct {
 	ExpiresIn time.Time `json:"expiresIn,omitempty" bson:"expiresIn,omitempty"`
 	Expiry    time.Time `json:"-"`
 
+	//- set by the token refresher when hubspot rejects the refresh token, cleared on the next successful connect
+	ReconnectRequired bool `json:"reconnectRequired,omitempty" bson:"reconnectRequired,omitempty"`
+
 	//- Redirect Url
 	RedirectUrlSuccess string `json:"redirectUrlSuccess" bson:"redirectUrlSuccess"`
 	RedirectUrlError   string `json:"redirectUrlError" bson:"redirectUrlError"`
@@ -0,0 +1,39 @@
+package domain
+
+import (
+	"time"
+)
+
+type TiktokOAuth struct {
+	//- compulsory fields
+	ClientKey string `json:"client_key,omitempty" bson:"client_key,omitempty"`
+	//- records created before the v2 migration have no version and belong to the v1 api
+	APIVersion string `json:"api_version,omitempty" bson:"api_version,omitempty"`
+
+	AccessToken  string `json:"access_token,omitempty" bson:"access_token,omitempty"`
+	RefreshToken string `json:"refresh_token,omitempty" bson:"refresh_token,omitempty"`
+
+	//- token extras returned by tiktok on code exchange
+	OpenID           string `json:"open_id,omitempty" bson:"open_id,omitempty"`
+	Scope            string `json:"scope,omitempty" bson:"scope,omitempty"`
+	RefreshExpiresIn int64  `json:"refresh_expires_in,omitempty" bson:"refresh_expires_in,omitempty"`
+
+	//- expiry
+	Expiry        time.Time `json:"expiry,omitempty" bson:"expiry,omitempty"`
+	RefreshExpiry time.Time `json:"refresh_expiry,omitempty" bson:"refresh_expiry,omitempty"`
+
+	//- set by the token refresher when tiktok rejects the refresh token, cleared on the next successful connect
+	ReconnectRequired bool `json:"reconnect_required,omitempty" bson:"reconnect_required,omitempty"`
+
+	CreatedAt time.Time `json:"created_at" bson:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" bson:"updated_at"`
+}
+
+// - Connected returns true once the OAuth flow has been completed for this client key
+func (o *TiktokOAuth) Connected() bool {
+	return o.AccessToken != "" && o.OpenID != ""
+}
+
+type TiktokOAuthKey struct {
+	ClientKey string `json:"client_key" bson:"client_key"`
+}
@@ -0,0 +1,20 @@
+package domain
+
+import (
+	"time"
+)
+
+type TiktokFileUploadInfo struct {
+	FileName        string `json:"file_name" bson:"file_name"`
+	FileSize        int64  `json:"file_size" bson:"file_size"`
+	FileContentType string `json:"file_content_type" bson:"file_content_type"`
+
+	//- content posting api result
+	PublishId     string   `json:"publish_id" bson:"publish_id"`
+	PublishStatus string   `json:"publish_status" bson:"publish_status"`
+	FailReason    string   `json:"fail_reason,omitempty" bson:"fail_reason,omitempty"`
+	PostIds       []string `json:"post_ids,omitempty" bson:"post_ids,omitempty"`
+
+	CreatedAt time.Time `json:"created_at" bson:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" bson:"updated_at"`
+}
@@ -0,0 +1,35 @@
+package domain
+
+import (
+	"time"
+)
+
+// - TusUpload is a resumable upload in progress, bytes up to Offset are stored on disk at FilePath
+type TusUpload struct {
+	Id        string            `json:"id" bson:"id"`
+	ClientKey string            `json:"client_key" bson:"client_key"`
+	Length    int64             `json:"length" bson:"length"`
+	Offset    int64             `json:"offset" bson:"offset"`
+	Metadata  map[string]string `json:"metadata" bson:"metadata"`
+	FilePath  string            `json:"file_path" bson:"file_path"`
+
+	//- validated when the upload is created, handed to the upload job once complete
+	FileInfo      *YoutubeFileUploadInfo `json:"file_info" bson:"file_info"`
+	VideoMetadata *YoutubeVideoMetadata  `json:"video_metadata" bson:"video_metadata"`
+
+	//- pushed back by every chunk, an unfinished upload is reaped with its file once it passed
+	ExpiresAt time.Time `json:"expires_at" bson:"expires_at"`
+
+	CreatedAt time.Time `json:"created_at" bson:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" bson:"updated_at"`
+}
+
+// - Completed returns true once every byte of the upload has been received
+func (u *TusUpload) Completed() bool {
+	return u.Offset >= u.Length
+}
+
+// - Expired returns true once an unfinished upload passed its expiration
+func (u *TusUpload) Expired(now time.Time) bool {
+	return !u.Completed() && !u.ExpiresAt.IsZero() && now.After(u.ExpiresAt)
+}
@@ -0,0 +1,26 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTusUploadExpired(t *testing.T) {
+	now := time.Date(2023, 9, 2, 12, 0, 0, 0, time.UTC)
+
+	upload := &TusUpload{Length: 10, Offset: 4, ExpiresAt: now.Add(-time.Minute)}
+	assert.True(t, upload.Expired(now))
+
+	upload.ExpiresAt = now.Add(time.Minute)
+	assert.False(t, upload.Expired(now))
+
+	//- a complete upload belongs to its job and never expires
+	upload = &TusUpload{Length: 10, Offset: 10, ExpiresAt: now.Add(-time.Minute)}
+	assert.False(t, upload.Expired(now))
+
+	//- saved before uploads had an expiration
+	upload = &TusUpload{Length: 10, Offset: 4}
+	assert.False(t, upload.Expired(now))
+}
@@ -0,0 +1,54 @@
+package domain
+
+import (
+	"time"
+
+	"google.golang.org/api/youtube/v3"
+)
+
+// - upload job states, a job only moves forward through them
+const (
+	UploadJobQueued     = "queued"
+	UploadJobUploading  = "uploading"
+	UploadJobProcessing = "processing"
+	UploadJobDone       = "done"
+	UploadJobFailed     = "failed"
+)
+
+// - UploadJob is a video upload handed over to the worker pool, the video waits on disk at FilePath
+type UploadJob struct {
+	Id        string `json:"id" bson:"id"`
+	ClientKey string `json:"client_key,omitempty" bson:"client_key"`
+	Status    string `json:"status" bson:"status"`
+
+	FilePath string                 `json:"file_path" bson:"file_path"`
+	FileInfo *YoutubeFileUploadInfo `json:"file_info,omitempty" bson:"file_info,omitempty"`
+	Metadata *YoutubeVideoMetadata  `json:"metadata,omitempty" bson:"metadata,omitempty"`
+	//- optional custom thumbnail, applied once the video is inserted
+	ThumbnailPath  string `json:"thumbnail_path,omitempty" bson:"thumbnail_path,omitempty"`
+	ThumbnailError string `json:"thumbnail_error,omitempty" bson:"thumbnail_error,omitempty"`
+	//- set once the video was added to Metadata.PlaylistId
+	PlaylistItemId string `json:"playlist_item_id,omitempty" bson:"playlist_item_id,omitempty"`
+	PlaylistError  string `json:"playlist_error,omitempty" bson:"playlist_error,omitempty"`
+
+	//- set while the job waits for the youtube quota to be reset
+	DeferredUntil *time.Time `json:"deferred_until,omitempty" bson:"deferred_until,omitempty"`
+
+	BytesUploaded int64 `json:"bytes_uploaded" bson:"bytes_uploaded"`
+	BytesTotal    int64 `json:"bytes_total" bson:"bytes_total"`
+
+	//- set once youtube accepted the video
+	VideoId            string                                            `json:"video_id,omitempty" bson:"video_id,omitempty"`
+	VideoURL           string                                            `json:"video_url,omitempty" bson:"video_url,omitempty"`
+	ProcessingStatus   string                                            `json:"processing_status,omitempty" bson:"processing_status,omitempty"`
+	ProcessingProgress *youtube.VideoProcessingDetailsProcessingProgress `json:"processing_progress,omitempty" bson:"processing_progress,omitempty"`
+	Error              string                                            `json:"error,omitempty" bson:"error,omitempty"`
+
+	CreatedAt time.Time `json:"created_at" bson:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" bson:"updated_at"`
+}
+
+// - Finished returns true once the job will not change anymore
+func (j *UploadJob) Finished() bool {
+	return j.Status == UploadJobDone || j.Status == UploadJobFailed
+}
@@ -0,0 +1,28 @@
+package domain
+
+import (
+	"fmt"
+)
+
+// - ValidationError reports input refused by the rules of the domain, the delivery answers it with a 400
+type ValidationError struct {
+	Err error
+}
+
+func (e *ValidationError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *ValidationError) Unwrap() error {
+	return e.Err
+}
+
+// - Invalid marks err as a validation error
+func Invalid(err error) error {
+	return &ValidationError{Err: err}
+}
+
+// - Invalidf formats a validation error like fmt.Errorf
+func Invalidf(format string, args ...interface{}) error {
+	return &ValidationError{Err: fmt.Errorf(format, args...)}
+}
@@ -0,0 +1,32 @@
+package domain
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// - limit enforced by youtube on captions.insert
+const YoutubeCaptionNameMaxLength = 150
+
+// - formats youtube can convert a caption track to on captions.download
+var YoutubeCaptionFormats = []string{"sbv", "scc", "srt", "ttml", "vtt"}
+
+// - YoutubeCaptionPayload describes a new caption track, the file itself is sent alongside
+type YoutubeCaptionPayload struct {
+	Language string `json:"language"`
+	Name     string `json:"name,omitempty"`
+	IsDraft  bool   `json:"is_draft"`
+}
+
+func (p *YoutubeCaptionPayload) Validate() error {
+	if strings.TrimSpace(p.Language) == "" {
+		return Invalidf("language is required")
+	}
+	if !languagePattern.MatchString(p.Language) {
+		return Invalidf("language must be a BCP-47 language code")
+	}
+	if utf8.RuneCountInString(p.Name) > YoutubeCaptionNameMaxLength {
+		return Invalidf("name must be at most %d characters", YoutubeCaptionNameMaxLength)
+	}
+	return nil
+}
@@ -0,0 +1,15 @@
+package domain
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestYoutubeCaptionPayload_Validate(t *testing.T) {
+	assert.Nil(t, (&YoutubeCaptionPayload{Language: "en-US", Name: "English"}).Validate())
+	assert.EqualError(t, (&YoutubeCaptionPayload{}).Validate(), "language is required")
+	assert.EqualError(t, (&YoutubeCaptionPayload{Language: "english!"}).Validate(), "language must be a BCP-47 language code")
+	assert.NotNil(t, (&YoutubeCaptionPayload{Language: "en", Name: strings.Repeat("a", YoutubeCaptionNameMaxLength+1)}).Validate())
+}
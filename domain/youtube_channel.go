@@ -0,0 +1,46 @@
+package domain
+
+import (
+	"time"
+
+	"google.golang.org/api/youtube/v3"
+)
+
+// - YoutubeChannel is the overview of a connected channel, statistics are as of FetchedAt
+type YoutubeChannel struct {
+	Id                string                    `json:"id"`
+	Title             string                    `json:"title"`
+	CustomUrl         string                    `json:"custom_url,omitempty"`
+	Thumbnails        *youtube.ThumbnailDetails `json:"thumbnails,omitempty"`
+	UploadsPlaylistId string                    `json:"uploads_playlist_id"`
+
+	SubscriberCount       uint64 `json:"subscriber_count"`
+	HiddenSubscriberCount bool   `json:"hidden_subscriber_count,omitempty"`
+	ViewCount             uint64 `json:"view_count"`
+	VideoCount            uint64 `json:"video_count"`
+
+	FetchedAt time.Time `json:"fetched_at"`
+}
+
+// - NewYoutubeChannel reads a channel returned by channels.list with the snippet, statistics and contentDetails parts
+func NewYoutubeChannel(channel *youtube.Channel) *YoutubeChannel {
+	overview := &YoutubeChannel{
+		Id:        channel.Id,
+		FetchedAt: timeNow(),
+	}
+	if channel.Snippet != nil {
+		overview.Title = channel.Snippet.Title
+		overview.CustomUrl = channel.Snippet.CustomUrl
+		overview.Thumbnails = channel.Snippet.Thumbnails
+	}
+	if channel.Statistics != nil {
+		overview.SubscriberCount = channel.Statistics.SubscriberCount
+		overview.HiddenSubscriberCount = channel.Statistics.HiddenSubscriberCount
+		overview.ViewCount = channel.Statistics.ViewCount
+		overview.VideoCount = channel.Statistics.VideoCount
+	}
+	if channel.ContentDetails != nil && channel.ContentDetails.RelatedPlaylists != nil {
+		overview.UploadsPlaylistId = channel.ContentDetails.RelatedPlaylists.Uploads
+	}
+	return overview
+}
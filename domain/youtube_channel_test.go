@@ -0,0 +1,44 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/api/youtube/v3"
+)
+
+func TestNewYoutubeChannel(t *testing.T) {
+	now := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	timeNow = func() time.Time { return now }
+	defer func() { timeNow = time.Now }()
+
+	channel := NewYoutubeChannel(&youtube.Channel{
+		Id: "UC123",
+		Snippet: &youtube.ChannelSnippet{
+			Title:      "Campaigns",
+			CustomUrl:  "@campaigns",
+			Thumbnails: &youtube.ThumbnailDetails{Default: &youtube.Thumbnail{Url: "https://example.com/a.jpg"}},
+		},
+		Statistics: &youtube.ChannelStatistics{SubscriberCount: 10, ViewCount: 200, VideoCount: 3},
+		ContentDetails: &youtube.ChannelContentDetails{
+			RelatedPlaylists: &youtube.ChannelContentDetailsRelatedPlaylists{Uploads: "UU123"},
+		},
+	})
+	assert.Equal(t, &YoutubeChannel{
+		Id:                "UC123",
+		Title:             "Campaigns",
+		CustomUrl:         "@campaigns",
+		Thumbnails:        &youtube.ThumbnailDetails{Default: &youtube.Thumbnail{Url: "https://example.com/a.jpg"}},
+		UploadsPlaylistId: "UU123",
+		SubscriberCount:   10,
+		ViewCount:         200,
+		VideoCount:        3,
+		FetchedAt:         now,
+	}, channel)
+
+	//- missing parts leave the fields empty instead of panicking
+	channel = NewYoutubeChannel(&youtube.Channel{Id: "UC123"})
+	assert.Equal(t, "UC123", channel.Id)
+	assert.Equal(t, "", channel.UploadsPlaylistId)
+}
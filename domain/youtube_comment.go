@@ -0,0 +1,101 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"google.golang.org/api/youtube/v3"
+)
+
+// - limit enforced by youtube on comments.insert
+const YoutubeCommentMaxLength = 10000
+
+// - moderation statuses accepted by the API, mapped to the ones of comments.setModerationStatus
+var YoutubeCommentModerationStatuses = map[string]string{
+	"held":      "heldForReview",
+	"published": "published",
+}
+
+type YoutubeCommentReplyPayload struct {
+	Text string `json:"text"`
+}
+
+func (p *YoutubeCommentReplyPayload) Validate() error {
+	if strings.TrimSpace(p.Text) == "" {
+		return Invalidf("text is required")
+	}
+	if utf8.RuneCountInString(p.Text) > YoutubeCommentMaxLength {
+		return Invalidf("text must be at most %d characters", YoutubeCommentMaxLength)
+	}
+	return nil
+}
+
+type YoutubeCommentModerationPayload struct {
+	Status string `json:"status"`
+}
+
+// - ModerationStatus returns the youtube name of the requested status
+func (p *YoutubeCommentModerationPayload) ModerationStatus() (string, error) {
+	status, ok := YoutubeCommentModerationStatuses[p.Status]
+	if !ok {
+		return "", Invalidf("status must be held or published")
+	}
+	return status, nil
+}
+
+// - YoutubeCommentThreadPage is one page of comment threads, newest first.
+// - On an incremental fetch Since is the previous fetch and Truncated tells that older new threads were left out,
+// - the next incremental fetch returns them unless this was the first one.
+type YoutubeCommentThreadPage struct {
+	Items      []*youtube.CommentThread `json:"items"`
+	NextCursor string                   `json:"next_cursor,omitempty"`
+	Since      *time.Time               `json:"since,omitempty"`
+	Truncated  bool                     `json:"truncated,omitempty"`
+}
+
+// - CommentThreadPublishedAt returns when the top level comment of the thread was posted
+func CommentThreadPublishedAt(thread *youtube.CommentThread) (time.Time, error) {
+	if thread.Snippet == nil || thread.Snippet.TopLevelComment == nil || thread.Snippet.TopLevelComment.Snippet == nil {
+		return time.Time{}, fmt.Errorf("comment thread %s has no top level comment", thread.Id)
+	}
+	return time.Parse(time.RFC3339, thread.Snippet.TopLevelComment.Snippet.PublishedAt)
+}
+
+// - YoutubeCommentWatermark is how far the incremental fetch of a video or channel got.
+// - Threads are compared to the second, so the ids already returned at PublishedAt are kept too.
+type YoutubeCommentWatermark struct {
+	PublishedAt time.Time `json:"published_at"`
+	ThreadIds   []string  `json:"thread_ids,omitempty"`
+
+	//- set after a truncated fetch, the next one resumes at PageToken and moves the watermark to Next once done
+	PageToken string                   `json:"page_token,omitempty"`
+	Next      *YoutubeCommentWatermark `json:"next,omitempty"`
+}
+
+// - Returned tells whether the thread was already returned by a previous fetch
+func (w *YoutubeCommentWatermark) Returned(threadId string, publishedAt time.Time) bool {
+	if publishedAt.Before(w.PublishedAt) {
+		return true
+	}
+	if publishedAt.Equal(w.PublishedAt) {
+		for _, id := range w.ThreadIds {
+			if id == threadId {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// - Add moves the watermark to the thread when it is the newest one returned so far
+func (w *YoutubeCommentWatermark) Add(threadId string, publishedAt time.Time) {
+	switch {
+	case publishedAt.After(w.PublishedAt):
+		w.PublishedAt = publishedAt
+		w.ThreadIds = []string{threadId}
+	case publishedAt.Equal(w.PublishedAt):
+		w.ThreadIds = append(w.ThreadIds, threadId)
+	}
+}
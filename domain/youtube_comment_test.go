@@ -0,0 +1,64 @@
+package domain
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/api/youtube/v3"
+)
+
+func TestYoutubeCommentReplyPayload_Validate(t *testing.T) {
+	assert.Nil(t, (&YoutubeCommentReplyPayload{Text: "Thanks!"}).Validate())
+	assert.EqualError(t, (&YoutubeCommentReplyPayload{Text: " "}).Validate(), "text is required")
+	assert.NotNil(t, (&YoutubeCommentReplyPayload{Text: strings.Repeat("a", YoutubeCommentMaxLength+1)}).Validate())
+}
+
+func TestYoutubeCommentModerationPayload_ModerationStatus(t *testing.T) {
+	status, err := (&YoutubeCommentModerationPayload{Status: "held"}).ModerationStatus()
+	assert.Nil(t, err)
+	assert.Equal(t, "heldForReview", status)
+
+	status, err = (&YoutubeCommentModerationPayload{Status: "published"}).ModerationStatus()
+	assert.Nil(t, err)
+	assert.Equal(t, "published", status)
+
+	_, err = (&YoutubeCommentModerationPayload{Status: "rejected"}).ModerationStatus()
+	assert.NotNil(t, err)
+}
+
+func TestCommentThreadPublishedAt(t *testing.T) {
+	thread := &youtube.CommentThread{
+		Snippet: &youtube.CommentThreadSnippet{
+			TopLevelComment: &youtube.Comment{
+				Snippet: &youtube.CommentSnippet{PublishedAt: "2024-03-01T10:00:00Z"},
+			},
+		},
+	}
+	publishedAt, err := CommentThreadPublishedAt(thread)
+	assert.Nil(t, err)
+	assert.Equal(t, time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC), publishedAt)
+
+	_, err = CommentThreadPublishedAt(&youtube.CommentThread{Id: "abc"})
+	assert.NotNil(t, err)
+}
+
+func TestYoutubeCommentWatermark(t *testing.T) {
+	boundary := time.Date(2023, 9, 1, 10, 0, 0, 0, time.UTC)
+	watermark := &YoutubeCommentWatermark{PublishedAt: boundary, ThreadIds: []string{"t1"}}
+
+	assert.True(t, watermark.Returned("t0", boundary.Add(-time.Second)))
+	assert.True(t, watermark.Returned("t1", boundary))
+	//- posted in the same second as the watermark but not returned yet
+	assert.False(t, watermark.Returned("t2", boundary))
+	assert.False(t, watermark.Returned("t3", boundary.Add(time.Second)))
+
+	watermark.Add("t2", boundary)
+	assert.Equal(t, []string{"t1", "t2"}, watermark.ThreadIds)
+	watermark.Add("t3", boundary.Add(time.Second))
+	assert.Equal(t, boundary.Add(time.Second), watermark.PublishedAt)
+	assert.Equal(t, []string{"t3"}, watermark.ThreadIds)
+	watermark.Add("t0", boundary.Add(-time.Second))
+	assert.Equal(t, []string{"t3"}, watermark.ThreadIds)
+}
@@ -0,0 +1,63 @@
+package domain
+
+import (
+	"strings"
+
+	"google.golang.org/api/youtube/v3"
+)
+
+const (
+	YoutubeEngagementBatchMaxSize = 500
+	//- videos.list accepts at most this many ids per call
+	YoutubeVideosListMaxIds = 50
+)
+
+// - per video statuses of a batch engagement lookup
+const (
+	YoutubeEngagementFound    = "ok"
+	YoutubeEngagementNotFound = "not_found"
+	YoutubeEngagementError    = "error"
+)
+
+type YoutubeEngagementBatchPayload struct {
+	ClientKey string   `json:"client_key"`
+	VideoIds  []string `json:"video_ids"`
+}
+
+func (p *YoutubeEngagementBatchPayload) Validate() error {
+	if p.ClientKey == "" {
+		return Invalidf("client key is required")
+	}
+	videoIds := p.UniqueVideoIds()
+	if len(videoIds) == 0 {
+		return Invalidf("video ids are required")
+	}
+	if len(videoIds) > YoutubeEngagementBatchMaxSize {
+		return Invalidf("at most %d video ids are allowed", YoutubeEngagementBatchMaxSize)
+	}
+	return nil
+}
+
+// - UniqueVideoIds drops blank and repeated ids, keeping the order of the request
+func (p *YoutubeEngagementBatchPayload) UniqueVideoIds() []string {
+	seen := map[string]bool{}
+	videoIds := []string{}
+	for _, videoId := range p.VideoIds {
+		videoId = strings.TrimSpace(videoId)
+		if videoId == "" || seen[videoId] {
+			continue
+		}
+		seen[videoId] = true
+		videoIds = append(videoIds, videoId)
+	}
+	return videoIds
+}
+
+// - YoutubeEngagementResult is the outcome of one video of a batch lookup
+type YoutubeEngagementResult struct {
+	VideoId    string                   `json:"video_id"`
+	Status     string                   `json:"status"`
+	Statistics *youtube.VideoStatistics `json:"statistics,omitempty"`
+	Cached     bool                     `json:"cached"`
+	Error      string                   `json:"error,omitempty"`
+}
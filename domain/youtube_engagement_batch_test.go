@@ -0,0 +1,25 @@
+package domain
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestYoutubeEngagementBatchPayload(t *testing.T) {
+	payload := &YoutubeEngagementBatchPayload{ClientKey: "abc", VideoIds: []string{"b", " a ", "", "b", "c"}}
+	assert.Nil(t, payload.Validate())
+	assert.Equal(t, []string{"b", "a", "c"}, payload.UniqueVideoIds())
+
+	assert.EqualError(t, (&YoutubeEngagementBatchPayload{VideoIds: []string{"a"}}).Validate(), "client key is required")
+	assert.EqualError(t, (&YoutubeEngagementBatchPayload{ClientKey: "abc", VideoIds: []string{" "}}).Validate(), "video ids are required")
+
+	videoIds := []string{}
+	for i := 0; i <= YoutubeEngagementBatchMaxSize; i++ {
+		videoIds = append(videoIds, fmt.Sprintf("video%d", i))
+	}
+	assert.NotNil(t, (&YoutubeEngagementBatchPayload{ClientKey: "abc", VideoIds: videoIds}).Validate())
+	//- repeated ids do not count twice
+	assert.Nil(t, (&YoutubeEngagementBatchPayload{ClientKey: "abc", VideoIds: append(videoIds[:10], videoIds[:10]...)}).Validate())
+}
@@ -0,0 +1,29 @@
+package domain
+
+import (
+	"time"
+
+	"google.golang.org/api/youtube/v3"
+)
+
+// - YoutubeCachedEngagement is the engagement kept in redis, it is fresh for MaxAge after FetchedAt
+// - and served stale until the redis key expires while a refresh runs in the background
+type YoutubeCachedEngagement struct {
+	Statistics *youtube.VideoStatistics `json:"statistics"`
+	FetchedAt  time.Time                `json:"fetched_at"`
+	MaxAge     Duration                 `json:"max_age"`
+}
+
+// - Age is the time since youtube was asked, in whole seconds as the Age header expects
+func (c *YoutubeCachedEngagement) Age(now time.Time) time.Duration {
+	age := now.Sub(c.FetchedAt).Truncate(time.Second)
+	if age < 0 {
+		return 0
+	}
+	return age
+}
+
+// - Stale tells whether the engagement has to be read again from youtube, entries without fetch time always are
+func (c *YoutubeCachedEngagement) Stale(now time.Time) bool {
+	return c.FetchedAt.IsZero() || c.Age(now) >= time.Duration(c.MaxAge)
+}
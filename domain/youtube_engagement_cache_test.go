@@ -0,0 +1,21 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestYoutubeCachedEngagement_Stale(t *testing.T) {
+	fetchedAt := time.Date(2023, 9, 1, 10, 0, 0, 0, time.UTC)
+	cached := &YoutubeCachedEngagement{FetchedAt: fetchedAt, MaxAge: Duration(15 * time.Minute)}
+
+	assert.Equal(t, 90*time.Second, cached.Age(fetchedAt.Add(90*time.Second+300*time.Millisecond)))
+	assert.Equal(t, time.Duration(0), cached.Age(fetchedAt.Add(-time.Minute)))
+
+	assert.False(t, cached.Stale(fetchedAt.Add(14*time.Minute)))
+	assert.True(t, cached.Stale(fetchedAt.Add(15*time.Minute)))
+
+	assert.True(t, (&YoutubeCachedEngagement{MaxAge: Duration(time.Hour)}).Stale(fetchedAt))
+}
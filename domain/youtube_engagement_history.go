@@ -0,0 +1,87 @@
+package domain
+
+import (
+	"time"
+
+	"google.golang.org/api/youtube/v3"
+)
+
+// - named intervals accepted by the history endpoint, any Go duration of at least a minute also works
+var YoutubeEngagementIntervals = map[string]time.Duration{
+	"hour": time.Hour,
+	"day":  24 * time.Hour,
+	"week": 7 * 24 * time.Hour,
+}
+
+// - YoutubeEngagementSnapshot is the statistics of a video at a point in time
+type YoutubeEngagementSnapshot struct {
+	At            time.Time `json:"at"`
+	ViewCount     uint64    `json:"view_count"`
+	LikeCount     uint64    `json:"like_count"`
+	CommentCount  uint64    `json:"comment_count"`
+	FavoriteCount uint64    `json:"favorite_count"`
+}
+
+func NewYoutubeEngagementSnapshot(statistics *youtube.VideoStatistics, at time.Time) *YoutubeEngagementSnapshot {
+	return &YoutubeEngagementSnapshot{
+		At:            at,
+		ViewCount:     statistics.ViewCount,
+		LikeCount:     statistics.LikeCount,
+		CommentCount:  statistics.CommentCount,
+		FavoriteCount: statistics.FavoriteCount,
+	}
+}
+
+// - YoutubeEngagementPoint is the last snapshot of an interval and its growth since the previous point
+type YoutubeEngagementPoint struct {
+	YoutubeEngagementSnapshot
+	ViewDelta     int64 `json:"view_delta"`
+	LikeDelta     int64 `json:"like_delta"`
+	CommentDelta  int64 `json:"comment_delta"`
+	FavoriteDelta int64 `json:"favorite_delta"`
+}
+
+type YoutubeEngagementHistory struct {
+	VideoId  string                    `json:"video_id"`
+	From     time.Time                 `json:"from"`
+	To       time.Time                 `json:"to"`
+	Interval string                    `json:"interval"`
+	Points   []*YoutubeEngagementPoint `json:"points"`
+}
+
+// - ParseEngagementInterval reads hour, day, week or a Go duration such as 6h
+func ParseEngagementInterval(value string) (time.Duration, error) {
+	if interval, ok := YoutubeEngagementIntervals[value]; ok {
+		return interval, nil
+	}
+	interval, err := time.ParseDuration(value)
+	if err != nil || interval < time.Minute {
+		return 0, Invalidf("interval must be hour, day, week or a duration of at least 1m")
+	}
+	return interval, nil
+}
+
+// - DownsampleEngagement keeps the last snapshot of every interval, intervals are aligned on the unix epoch.
+// - Snapshots must be sorted by time. The first point has no previous point and no growth.
+func DownsampleEngagement(snapshots []*YoutubeEngagementSnapshot, interval time.Duration) []*YoutubeEngagementPoint {
+	points := []*YoutubeEngagementPoint{}
+	var bucket time.Time
+	for _, snapshot := range snapshots {
+		current := snapshot.At.Truncate(interval)
+		if len(points) > 0 && current.Equal(bucket) {
+			points[len(points)-1].YoutubeEngagementSnapshot = *snapshot
+			continue
+		}
+		bucket = current
+		points = append(points, &YoutubeEngagementPoint{YoutubeEngagementSnapshot: *snapshot})
+	}
+
+	for i := 1; i < len(points); i++ {
+		previous, point := points[i-1], points[i]
+		point.ViewDelta = int64(point.ViewCount) - int64(previous.ViewCount)
+		point.LikeDelta = int64(point.LikeCount) - int64(previous.LikeCount)
+		point.CommentDelta = int64(point.CommentCount) - int64(previous.CommentCount)
+		point.FavoriteDelta = int64(point.FavoriteCount) - int64(previous.FavoriteCount)
+	}
+	return points
+}
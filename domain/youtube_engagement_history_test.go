@@ -0,0 +1,55 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseEngagementInterval(t *testing.T) {
+	interval, err := ParseEngagementInterval("day")
+	assert.Nil(t, err)
+	assert.Equal(t, 24*time.Hour, interval)
+
+	interval, err = ParseEngagementInterval("6h")
+	assert.Nil(t, err)
+	assert.Equal(t, 6*time.Hour, interval)
+
+	_, err = ParseEngagementInterval("10s")
+	assert.NotNil(t, err)
+
+	_, err = ParseEngagementInterval("month")
+	assert.NotNil(t, err)
+}
+
+func TestDownsampleEngagement(t *testing.T) {
+	start := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	snapshot := func(offset time.Duration, views uint64, likes uint64) *YoutubeEngagementSnapshot {
+		return &YoutubeEngagementSnapshot{At: start.Add(offset), ViewCount: views, LikeCount: likes}
+	}
+	snapshots := []*YoutubeEngagementSnapshot{
+		snapshot(10*time.Minute, 100, 1),
+		snapshot(50*time.Minute, 150, 2),
+		snapshot(70*time.Minute, 200, 2),
+		//- no snapshot between 2:00 and 3:00, the gap is not filled
+		snapshot(3*time.Hour+5*time.Minute, 500, 1),
+	}
+
+	points := DownsampleEngagement(snapshots, time.Hour)
+	assert.Len(t, points, 3)
+
+	assert.Equal(t, start.Add(50*time.Minute), points[0].At)
+	assert.Equal(t, uint64(150), points[0].ViewCount)
+	assert.Equal(t, int64(0), points[0].ViewDelta)
+
+	assert.Equal(t, start.Add(70*time.Minute), points[1].At)
+	assert.Equal(t, int64(50), points[1].ViewDelta)
+	assert.Equal(t, int64(0), points[1].LikeDelta)
+
+	assert.Equal(t, int64(300), points[2].ViewDelta)
+	//- likes can go down
+	assert.Equal(t, int64(-1), points[2].LikeDelta)
+
+	assert.Empty(t, DownsampleEngagement(nil, time.Hour))
+}
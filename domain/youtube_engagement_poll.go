@@ -0,0 +1,61 @@
+package domain
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// - polling more often than this would burn quota for numbers youtube only refreshes every few minutes
+const YoutubeEngagementPollMinInterval = time.Minute
+
+// - Duration is a time.Duration written as "1h30m" in JSON
+type Duration time.Duration
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var value string
+	if err := json.Unmarshal(data, &value); err != nil {
+		return Invalidf("duration must be a string such as 1h or 30m")
+	}
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		return Invalidf("duration must be a string such as 1h or 30m")
+	}
+	*d = Duration(duration)
+	return nil
+}
+
+// - YoutubeEngagementPollSettings sets how often the engagement of the videos uploaded by a client key is refreshed:
+// - every EarlyInterval during the EarlyPeriod after the upload, then every LateInterval
+type YoutubeEngagementPollSettings struct {
+	EarlyInterval Duration `json:"early_interval"`
+	EarlyPeriod   Duration `json:"early_period"`
+	LateInterval  Duration `json:"late_interval"`
+	Disabled      bool     `json:"disabled"`
+}
+
+func (s *YoutubeEngagementPollSettings) Validate() error {
+	if time.Duration(s.EarlyInterval) < YoutubeEngagementPollMinInterval || time.Duration(s.LateInterval) < YoutubeEngagementPollMinInterval {
+		return Invalidf("intervals must be at least %s", YoutubeEngagementPollMinInterval)
+	}
+	if s.EarlyPeriod < 0 {
+		return Invalidf("early period must not be negative")
+	}
+	return nil
+}
+
+// - Interval returns the polling interval of a video uploaded at uploadedAt
+func (s *YoutubeEngagementPollSettings) Interval(uploadedAt time.Time, now time.Time) time.Duration {
+	if now.Sub(uploadedAt) < time.Duration(s.EarlyPeriod) {
+		return time.Duration(s.EarlyInterval)
+	}
+	return time.Duration(s.LateInterval)
+}
+
+// - NextPoll returns when a video uploaded at uploadedAt is due again after being polled at now
+func (s *YoutubeEngagementPollSettings) NextPoll(uploadedAt time.Time, now time.Time) time.Time {
+	return now.Add(s.Interval(uploadedAt, now))
+}
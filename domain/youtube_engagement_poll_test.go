@@ -0,0 +1,48 @@
+package domain
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestYoutubeEngagementPollSettings_JSON(t *testing.T) {
+	settings := &YoutubeEngagementPollSettings{}
+	err := json.Unmarshal([]byte(`{"early_interval":"1h","early_period":"48h","late_interval":"24h"}`), settings)
+	assert.Nil(t, err)
+	assert.Equal(t, Duration(time.Hour), settings.EarlyInterval)
+	assert.Equal(t, Duration(48*time.Hour), settings.EarlyPeriod)
+	assert.Nil(t, settings.Validate())
+
+	data, err := json.Marshal(settings)
+	assert.Nil(t, err)
+	assert.JSONEq(t, `{"early_interval":"1h0m0s","early_period":"48h0m0s","late_interval":"24h0m0s","disabled":false}`, string(data))
+
+	err = json.Unmarshal([]byte(`{"early_interval":3600}`), settings)
+	assert.NotNil(t, err)
+}
+
+func TestYoutubeEngagementPollSettings_Validate(t *testing.T) {
+	settings := &YoutubeEngagementPollSettings{EarlyInterval: Duration(30 * time.Second), LateInterval: Duration(time.Hour)}
+	assert.NotNil(t, settings.Validate())
+
+	settings = &YoutubeEngagementPollSettings{EarlyInterval: Duration(time.Hour), LateInterval: Duration(time.Hour), EarlyPeriod: Duration(-time.Hour)}
+	assert.NotNil(t, settings.Validate())
+}
+
+func TestYoutubeEngagementPollSettings_NextPoll(t *testing.T) {
+	settings := &YoutubeEngagementPollSettings{
+		EarlyInterval: Duration(time.Hour),
+		EarlyPeriod:   Duration(48 * time.Hour),
+		LateInterval:  Duration(24 * time.Hour),
+	}
+	uploadedAt := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	now := uploadedAt.Add(47 * time.Hour)
+	assert.Equal(t, now.Add(time.Hour), settings.NextPoll(uploadedAt, now))
+
+	now = uploadedAt.Add(48 * time.Hour)
+	assert.Equal(t, now.Add(24*time.Hour), settings.NextPoll(uploadedAt, now))
+}
@@ -20,6 +20,9 @@ type YoutubeOAuth struct {
 	//- expiry
 	ExpiresIn time.Time `json:"-"`
 	Expiry    time.Time `json:"expiry,omitempty" bson:"expiry,omitempty"`
+
+	//- set by the token refresher when google rejects the refresh token, cleared on the next successful connect
+	ReconnectRequired bool `json:"reconnect_required,omitempty" bson:"reconnect_required,omitempty"`
 }
 
 func (o *OAuth) setYoutubeExpiresIn() {
@@ -0,0 +1,79 @@
+package domain
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/exp/slices"
+	"google.golang.org/api/youtube/v3"
+)
+
+// - limits enforced by youtube on playlists.insert
+const (
+	YoutubePlaylistTitleMaxLength       = 150
+	YoutubePlaylistDescriptionMaxLength = 5000
+	YoutubeDefaultPlaylistPrivacyStatus = "private"
+
+	YoutubePlaylistPageDefaultSize = 25
+	YoutubePlaylistPageMaxSize     = 50
+)
+
+// - YoutubePlaylistPayload creates or changes a playlist, on update fields left out keep their current value
+type YoutubePlaylistPayload struct {
+	Title         *string `json:"title,omitempty"`
+	Description   *string `json:"description,omitempty"`
+	PrivacyStatus *string `json:"privacy_status,omitempty"`
+}
+
+// - Apply merges the payload into the playlist and checks the result, a new playlist starts from an empty one
+func (p *YoutubePlaylistPayload) Apply(playlist *youtube.Playlist) error {
+	if playlist.Snippet == nil {
+		playlist.Snippet = &youtube.PlaylistSnippet{}
+	}
+	if playlist.Status == nil {
+		playlist.Status = &youtube.PlaylistStatus{PrivacyStatus: YoutubeDefaultPlaylistPrivacyStatus}
+	}
+
+	title, description, privacyStatus := playlist.Snippet.Title, playlist.Snippet.Description, playlist.Status.PrivacyStatus
+	if p.Title != nil {
+		title = *p.Title
+	}
+	if p.Description != nil {
+		description = *p.Description
+	}
+	if p.PrivacyStatus != nil {
+		privacyStatus = *p.PrivacyStatus
+	}
+
+	if strings.TrimSpace(title) == "" {
+		return Invalidf("title is required")
+	}
+	if utf8.RuneCountInString(title) > YoutubePlaylistTitleMaxLength {
+		return Invalidf("title must be at most %d characters", YoutubePlaylistTitleMaxLength)
+	}
+	if utf8.RuneCountInString(description) > YoutubePlaylistDescriptionMaxLength {
+		return Invalidf("description must be at most %d characters", YoutubePlaylistDescriptionMaxLength)
+	}
+	if !slices.Contains(YoutubePrivacyStatuses, privacyStatus) {
+		return Invalidf("privacy status must be one of %s", strings.Join(YoutubePrivacyStatuses, ", "))
+	}
+
+	playlist.Snippet.Title = title
+	playlist.Snippet.Description = description
+	playlist.Status.PrivacyStatus = privacyStatus
+	return nil
+}
+
+// - YoutubePlaylistItemPayload adds a video to a playlist or moves an item, items go last when no position is given
+type YoutubePlaylistItemPayload struct {
+	VideoId  string `json:"video_id,omitempty"`
+	Position *int64 `json:"position,omitempty"`
+}
+
+// - YoutubePlaylistItemPage is one page of playlist items, cursors are opaque and empty on the first and last page
+type YoutubePlaylistItemPage struct {
+	Items        []*youtube.PlaylistItem `json:"items"`
+	NextCursor   string                  `json:"next_cursor,omitempty"`
+	PrevCursor   string                  `json:"prev_cursor,omitempty"`
+	TotalResults int64                   `json:"total_results"`
+}
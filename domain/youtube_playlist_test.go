@@ -0,0 +1,36 @@
+package domain
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/api/youtube/v3"
+)
+
+func TestYoutubePlaylistPayload_Apply(t *testing.T) {
+	title := "Summer campaign"
+	playlist := &youtube.Playlist{}
+	err := (&YoutubePlaylistPayload{Title: &title}).Apply(playlist)
+	assert.Nil(t, err)
+	assert.Equal(t, "Summer campaign", playlist.Snippet.Title)
+	assert.Equal(t, "private", playlist.Status.PrivacyStatus)
+
+	public := "public"
+	err = (&YoutubePlaylistPayload{PrivacyStatus: &public}).Apply(playlist)
+	assert.Nil(t, err)
+	assert.Equal(t, "Summer campaign", playlist.Snippet.Title)
+	assert.Equal(t, "public", playlist.Status.PrivacyStatus)
+
+	err = (&YoutubePlaylistPayload{}).Apply(&youtube.Playlist{})
+	assert.EqualError(t, err, "title is required")
+
+	long := strings.Repeat("a", YoutubePlaylistTitleMaxLength+1)
+	err = (&YoutubePlaylistPayload{Title: &long}).Apply(playlist)
+	assert.NotNil(t, err)
+	assert.Equal(t, "Summer campaign", playlist.Snippet.Title)
+
+	hidden := "hidden"
+	err = (&YoutubePlaylistPayload{PrivacyStatus: &hidden}).Apply(playlist)
+	assert.NotNil(t, err)
+}
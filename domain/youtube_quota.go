@@ -0,0 +1,91 @@
+package domain
+
+import (
+	"net/url"
+	"strings"
+	"time"
+	_ "time/tzdata" //- the quota day follows Pacific time even on hosts without a zoneinfo database
+)
+
+const (
+	//- daily quota granted to a google cloud project unless an extension was approved
+	YoutubeQuotaDefaultBudget   = 10000
+	YoutubeQuotaVideoInsertCost = 1600
+)
+
+// - unit cost of the calls which are not 1 for reads and 50 for writes, keyed by method and resource
+var youtubeQuotaCosts = map[string]int64{
+	"POST videos":   YoutubeQuotaVideoInsertCost,
+	"GET captions":  50,
+	"POST captions": 400,
+	"PUT captions":  450,
+	"GET search":    100,
+}
+
+// - google resets the quota of every project at midnight Pacific time
+var youtubeQuotaLocation = quotaLocation()
+
+func quotaLocation() *time.Location {
+	location, err := time.LoadLocation("America/Los_Angeles")
+	if err != nil {
+		return time.FixedZone("PST", -8*60*60)
+	}
+	return location
+}
+
+// - YoutubeQuotaCost returns the units a call to the YouTube Data API is charged,
+// - the chunks of a resumable upload are covered by the insert which opened the session
+func YoutubeQuotaCost(method string, u *url.URL) int64 {
+	if strings.HasPrefix(u.Path, "/upload/") && u.Query().Get("upload_id") != "" {
+		return 0
+	}
+
+	resource := strings.TrimPrefix(strings.TrimPrefix(u.Path, "/upload"), "/youtube/v3/")
+	if method == "GET" && strings.HasPrefix(resource, "captions/") {
+		//- captions.download
+		return 200
+	}
+	if cost, ok := youtubeQuotaCosts[method+" "+resource]; ok {
+		return cost
+	}
+	if method == "GET" {
+		return 1
+	}
+	return 50
+}
+
+// - YoutubeQuotaDay is the Pacific date the usage at now is counted on
+func YoutubeQuotaDay(now time.Time) string {
+	return now.In(youtubeQuotaLocation).Format("2006-01-02")
+}
+
+// - YoutubeQuotaResetAt is the next Pacific midnight after now
+func YoutubeQuotaResetAt(now time.Time) time.Time {
+	pacific := now.In(youtubeQuotaLocation)
+	return time.Date(pacific.Year(), pacific.Month(), pacific.Day()+1, 0, 0, 0, 0, youtubeQuotaLocation)
+}
+
+// - YoutubeQuotaUsage is the quota used today by a google cloud project against the configured budget
+type YoutubeQuotaUsage struct {
+	Project   string    `json:"project"`
+	Day       string    `json:"day"`
+	Used      int64     `json:"used"`
+	Budget    int64     `json:"budget"`
+	Remaining int64     `json:"remaining"`
+	ResetAt   time.Time `json:"reset_at"`
+}
+
+func NewYoutubeQuotaUsage(project string, used int64, budget int64, now time.Time) *YoutubeQuotaUsage {
+	remaining := budget - used
+	if remaining < 0 {
+		remaining = 0
+	}
+	return &YoutubeQuotaUsage{
+		Project:   project,
+		Day:       YoutubeQuotaDay(now),
+		Used:      used,
+		Budget:    budget,
+		Remaining: remaining,
+		ResetAt:   YoutubeQuotaResetAt(now),
+	}
+}
@@ -0,0 +1,56 @@
+package domain
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestYoutubeQuotaCost(t *testing.T) {
+	cases := []struct {
+		method string
+		url    string
+		cost   int64
+	}{
+		{"GET", "https://youtube.googleapis.com/youtube/v3/videos?part=statistics&id=abc", 1},
+		{"POST", "https://youtube.googleapis.com/upload/youtube/v3/videos?uploadType=resumable&part=snippet", 1600},
+		{"PUT", "https://youtube.googleapis.com/upload/youtube/v3/videos?uploadType=resumable&upload_id=xyz", 0},
+		{"PUT", "https://youtube.googleapis.com/youtube/v3/videos?part=snippet", 50},
+		{"DELETE", "https://youtube.googleapis.com/youtube/v3/videos?id=abc", 50},
+		{"POST", "https://youtube.googleapis.com/upload/youtube/v3/thumbnails/set?videoId=abc", 50},
+		{"GET", "https://youtube.googleapis.com/youtube/v3/captions?part=snippet&videoId=abc", 50},
+		{"GET", "https://youtube.googleapis.com/youtube/v3/captions/cap1?tfmt=srt", 200},
+		{"POST", "https://youtube.googleapis.com/upload/youtube/v3/captions?uploadType=multipart", 400},
+		{"PUT", "https://youtube.googleapis.com/upload/youtube/v3/captions?uploadType=multipart", 450},
+		{"GET", "https://youtube.googleapis.com/youtube/v3/search?part=id", 100},
+		{"POST", "https://youtube.googleapis.com/youtube/v3/comments/setModerationStatus?id=c1", 50},
+	}
+	for _, c := range cases {
+		u, err := url.Parse(c.url)
+		assert.Nil(t, err)
+		assert.Equal(t, c.cost, YoutubeQuotaCost(c.method, u), c.method+" "+c.url)
+	}
+}
+
+func TestYoutubeQuotaDay(t *testing.T) {
+	//- 06:30 UTC is still the previous day in California
+	now := time.Date(2023, 9, 2, 6, 30, 0, 0, time.UTC)
+	assert.Equal(t, "2023-09-01", YoutubeQuotaDay(now))
+	assert.Equal(t, time.Date(2023, 9, 2, 7, 0, 0, 0, time.UTC), YoutubeQuotaResetAt(now).UTC())
+
+	now = time.Date(2023, 12, 2, 8, 30, 0, 0, time.UTC)
+	assert.Equal(t, "2023-12-02", YoutubeQuotaDay(now))
+	assert.Equal(t, time.Date(2023, 12, 3, 8, 0, 0, 0, time.UTC), YoutubeQuotaResetAt(now).UTC())
+}
+
+func TestNewYoutubeQuotaUsage(t *testing.T) {
+	now := time.Date(2023, 9, 1, 12, 0, 0, 0, time.UTC)
+	usage := NewYoutubeQuotaUsage("project", 9800, 10000, now)
+	assert.Equal(t, int64(200), usage.Remaining)
+	assert.Equal(t, "2023-09-01", usage.Day)
+
+	usage = NewYoutubeQuotaUsage("project", 10050, 10000, now)
+	assert.Equal(t, int64(0), usage.Remaining)
+}
@@ -11,7 +11,23 @@ type YoutubeFileUploadInfo struct {
 	FileSize        int64                    `json:"file_size" bson:"file_size"`
 	FileContentType string                   `json:"file_content_type" bson:"file_content_type"`
 	VideoEngagement *youtube.VideoStatistics `json:"video_engagement" bson:"video_engagement"`
+	VideoId         string                   `json:"video_id,omitempty" bson:"video_id,omitempty"`
+	PublishAt       *time.Time               `json:"publish_at,omitempty" bson:"publish_at,omitempty"`
 
 	CreatedAt time.Time `json:"created_at" bson:"created_at"`
 	UpdatedAt time.Time `json:"updated_at" bson:"updated_at"`
 }
+
+// - YoutubeScheduledVideo is a private video waiting for youtube to publish it at PublishAt
+type YoutubeScheduledVideo struct {
+	VideoId   string    `json:"video_id" bson:"video_id"`
+	Title     string    `json:"title" bson:"title"`
+	PublishAt time.Time `json:"publish_at" bson:"publish_at"`
+
+	CreatedAt time.Time `json:"created_at" bson:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" bson:"updated_at"`
+}
+
+type YoutubeSchedulePayload struct {
+	PublishAt time.Time `json:"publish_at"`
+}
@@ -0,0 +1,190 @@
+package domain
+
+import (
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"golang.org/x/exp/slices"
+	"google.golang.org/api/youtube/v3"
+)
+
+// - limits enforced by youtube on videos.insert, checked before any quota is spent
+const (
+	YoutubeTitleMaxLength       = 100
+	YoutubeDescriptionMaxLength = 5000
+	YoutubeTagsMaxLength        = 500
+
+	YoutubeDefaultCategoryId    = "22" //- People & Blogs
+	YoutubeDefaultPrivacyStatus = "unlisted"
+	YoutubeDefaultLicense       = "youtube"
+)
+
+var YoutubePrivacyStatuses = []string{"private", "public", "unlisted"}
+var YoutubeLicenses = []string{"youtube", "creativeCommon"}
+
+var categoryIdPattern = regexp.MustCompile(`^[0-9]+$`)
+var languagePattern = regexp.MustCompile(`^[a-zA-Z]{2,3}(-[a-zA-Z0-9]{2,8})*$`)
+var playlistIdPattern = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
+
+type YoutubeVideoMetadata struct {
+	Title           string   `json:"title"`
+	Description     string   `json:"description,omitempty"`
+	Tags            []string `json:"tags,omitempty"`
+	CategoryId      string   `json:"category_id,omitempty"`
+	PrivacyStatus   string   `json:"privacy_status,omitempty"`
+	DefaultLanguage string   `json:"default_language,omitempty"`
+	MadeForKids     bool     `json:"made_for_kids"`
+	License         string   `json:"license,omitempty"`
+	Embeddable      *bool    `json:"embeddable,omitempty"`
+
+	//- scheduled videos are uploaded as private and made public by youtube at this time
+	PublishAt *time.Time `json:"publish_at,omitempty"`
+
+	//- the video is added to this playlist once uploaded, not part of the youtube video resource
+	PlaylistId string `json:"playlist_id,omitempty"`
+}
+
+// - SetDefaults fills the optional fields youtube would otherwise reject or guess
+func (m *YoutubeVideoMetadata) SetDefaults() {
+	if m.CategoryId == "" {
+		m.CategoryId = YoutubeDefaultCategoryId
+	}
+	if m.PrivacyStatus == "" && m.PublishAt != nil {
+		m.PrivacyStatus = "private"
+	}
+	if m.PrivacyStatus == "" {
+		m.PrivacyStatus = YoutubeDefaultPrivacyStatus
+	}
+	if m.License == "" {
+		m.License = YoutubeDefaultLicense
+	}
+	if m.Embeddable == nil {
+		embeddable := true
+		m.Embeddable = &embeddable
+	}
+}
+
+// - Validate checks metadata received from a client, on top of the youtube limits a publish time has to be in the future
+func (m *YoutubeVideoMetadata) Validate() error {
+	if err := m.ValidateLimits(); err != nil {
+		return err
+	}
+	if m.PublishAtPassed() {
+		return Invalidf("publish at must be in the future")
+	}
+	return nil
+}
+
+// - ValidateLimits checks the metadata against youtube limits, lengths are counted in characters.
+// - The publish time is not checked, it may have passed while an accepted upload waited for its turn.
+func (m *YoutubeVideoMetadata) ValidateLimits() error {
+	if strings.TrimSpace(m.Title) == "" {
+		return Invalidf("title is required")
+	}
+	if utf8.RuneCountInString(m.Title) > YoutubeTitleMaxLength {
+		return Invalidf("title must be at most %d characters", YoutubeTitleMaxLength)
+	}
+	//- youtube rejects angle brackets in title and description
+	if strings.ContainsAny(m.Title, "<>") {
+		return Invalidf("title must not contain < or >")
+	}
+	if utf8.RuneCountInString(m.Description) > YoutubeDescriptionMaxLength {
+		return Invalidf("description must be at most %d characters", YoutubeDescriptionMaxLength)
+	}
+	if strings.ContainsAny(m.Description, "<>") {
+		return Invalidf("description must not contain < or >")
+	}
+	if tagsLength(m.Tags) > YoutubeTagsMaxLength {
+		return Invalidf("tags must be at most %d characters in total", YoutubeTagsMaxLength)
+	}
+	if m.CategoryId != "" && !categoryIdPattern.MatchString(m.CategoryId) {
+		return Invalidf("category id must be numeric")
+	}
+	if m.PrivacyStatus != "" && !slices.Contains(YoutubePrivacyStatuses, m.PrivacyStatus) {
+		return Invalidf("privacy status must be one of %s", strings.Join(YoutubePrivacyStatuses, ", "))
+	}
+	if m.PublishAt != nil && m.PrivacyStatus != "private" {
+		return Invalidf("scheduled videos must stay private until published")
+	}
+	if m.DefaultLanguage != "" && !languagePattern.MatchString(m.DefaultLanguage) {
+		return Invalidf("default language must be a BCP-47 language code")
+	}
+	if m.License != "" && !slices.Contains(YoutubeLicenses, m.License) {
+		return Invalidf("license must be one of %s", strings.Join(YoutubeLicenses, ", "))
+	}
+	if m.PlaylistId != "" && !playlistIdPattern.MatchString(m.PlaylistId) {
+		return Invalidf("playlist id is invalid")
+	}
+	return nil
+}
+
+// - PublishAtPassed tells whether the video was scheduled for a time that is already over
+func (m *YoutubeVideoMetadata) PublishAtPassed() bool {
+	return m.PublishAt != nil && !m.PublishAt.After(timeNow())
+}
+
+// - PublishNow drops a schedule that passed, the video is made public as it would have been by then
+func (m *YoutubeVideoMetadata) PublishNow() {
+	m.PublishAt = nil
+	m.PrivacyStatus = "public"
+}
+
+// - Video builds the youtube resource sent on videos.insert
+func (m *YoutubeVideoMetadata) Video() *youtube.Video {
+	video := &youtube.Video{
+		Snippet: &youtube.VideoSnippet{
+			Title:                m.Title,
+			Description:          m.Description,
+			CategoryId:           m.CategoryId,
+			DefaultLanguage:      m.DefaultLanguage,
+			DefaultAudioLanguage: m.DefaultLanguage,
+		},
+		Status: &youtube.VideoStatus{
+			PrivacyStatus:           m.PrivacyStatus,
+			SelfDeclaredMadeForKids: m.MadeForKids,
+			License:                 m.License,
+			//- false values are dropped by the client unless forced
+			ForceSendFields: []string{"SelfDeclaredMadeForKids", "Embeddable"},
+		},
+	}
+	if m.Embeddable != nil {
+		video.Status.Embeddable = *m.Embeddable
+	}
+	if m.PublishAt != nil {
+		video.Status.PublishAt = m.PublishAt.UTC().Format(time.RFC3339)
+	}
+	//- the API returns a 400 Bad Request response if tags is an empty string
+	if len(m.Tags) > 0 {
+		video.Snippet.Tags = m.Tags
+	}
+	return video
+}
+
+// - ParseYoutubeTags splits a comma separated tag list, dropping empty entries
+func ParseYoutubeTags(value string) []string {
+	var tags []string
+	for _, tag := range strings.Split(value, ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+// - tagsLength counts tags the way youtube does: commas between tags count
+// - and tags containing spaces are wrapped in quotes
+func tagsLength(tags []string) int {
+	length := 0
+	for i, tag := range tags {
+		length += utf8.RuneCountInString(tag)
+		if strings.Contains(tag, " ") {
+			length += 2
+		}
+		if i > 0 {
+			length++
+		}
+	}
+	return length
+}
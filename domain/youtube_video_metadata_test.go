@@ -0,0 +1,128 @@
+package domain
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestYoutubeVideoMetadata_Validate(t *testing.T) {
+	tests := []struct {
+		name     string
+		metadata YoutubeVideoMetadata
+		wantErr  string
+	}{
+		{
+			name:     "valid metadata",
+			metadata: YoutubeVideoMetadata{Title: "My video", Tags: []string{"go", "redis"}, PrivacyStatus: "public", DefaultLanguage: "en-US"},
+		},
+		{
+			name:     "missing title",
+			metadata: YoutubeVideoMetadata{Title: "  "},
+			wantErr:  "title is required",
+		},
+		{
+			name:     "title counted in characters",
+			metadata: YoutubeVideoMetadata{Title: strings.Repeat("é", 100)},
+		},
+		{
+			name:     "title too long",
+			metadata: YoutubeVideoMetadata{Title: strings.Repeat("a", 101)},
+			wantErr:  "title must be at most 100 characters",
+		},
+		{
+			name:     "description too long",
+			metadata: YoutubeVideoMetadata{Title: "My video", Description: strings.Repeat("a", 5001)},
+			wantErr:  "description must be at most 5000 characters",
+		},
+		{
+			name:     "angle brackets",
+			metadata: YoutubeVideoMetadata{Title: "<b>My video</b>"},
+			wantErr:  "title must not contain < or >",
+		},
+		{
+			//- 250 + 1 comma + 247 + 2 quotes = 500
+			name:     "tags at the limit",
+			metadata: YoutubeVideoMetadata{Title: "My video", Tags: []string{strings.Repeat("a", 250), strings.Repeat("b", 123) + " " + strings.Repeat("c", 123)}},
+		},
+		{
+			name:     "tags over the limit",
+			metadata: YoutubeVideoMetadata{Title: "My video", Tags: []string{strings.Repeat("a", 250), strings.Repeat("b", 124) + " " + strings.Repeat("c", 123)}},
+			wantErr:  "tags must be at most 500 characters in total",
+		},
+		{
+			name:     "unknown privacy status",
+			metadata: YoutubeVideoMetadata{Title: "My video", PrivacyStatus: "friends"},
+			wantErr:  "privacy status must be one of private, public, unlisted",
+		},
+		{
+			name:     "non numeric category",
+			metadata: YoutubeVideoMetadata{Title: "My video", CategoryId: "music"},
+			wantErr:  "category id must be numeric",
+		},
+		{
+			name:     "unknown license",
+			metadata: YoutubeVideoMetadata{Title: "My video", License: "mit"},
+			wantErr:  "license must be one of youtube, creativeCommon",
+		},
+	}
+
+	for _, testCase := range tests {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			err := testCase.metadata.Validate()
+			if testCase.wantErr == "" {
+				assert.Nil(t, err)
+				return
+			}
+			assert.EqualError(t, err, testCase.wantErr)
+		})
+	}
+}
+
+func TestYoutubeVideoMetadata_Video(t *testing.T) {
+	metadata := &YoutubeVideoMetadata{Title: "My video", Tags: ParseYoutubeTags(" go, ,redis ")}
+	metadata.SetDefaults()
+
+	video := metadata.Video()
+	assert.Equal(t, []string{"go", "redis"}, video.Snippet.Tags)
+	assert.Equal(t, YoutubeDefaultCategoryId, video.Snippet.CategoryId)
+	assert.Equal(t, YoutubeDefaultPrivacyStatus, video.Status.PrivacyStatus)
+	assert.True(t, video.Status.Embeddable)
+	assert.False(t, video.Status.SelfDeclaredMadeForKids)
+}
+
+func TestYoutubeVideoMetadata_PublishAt(t *testing.T) {
+	past := time.Now().Add(-time.Hour)
+	metadata := &YoutubeVideoMetadata{Title: "My video", PublishAt: &past}
+	metadata.SetDefaults()
+	assert.EqualError(t, metadata.Validate(), "publish at must be in the future")
+
+	future := time.Date(2100, 1, 2, 3, 4, 5, 0, time.FixedZone("ICT", 7*60*60))
+	metadata = &YoutubeVideoMetadata{Title: "My video", PublishAt: &future}
+	metadata.SetDefaults()
+	assert.Nil(t, metadata.Validate())
+	video := metadata.Video()
+	assert.Equal(t, "private", video.Status.PrivacyStatus)
+	assert.Equal(t, "2100-01-01T20:04:05Z", video.Status.PublishAt)
+
+	metadata = &YoutubeVideoMetadata{Title: "My video", PrivacyStatus: "public", PublishAt: &future}
+	assert.EqualError(t, metadata.Validate(), "scheduled videos must stay private until published")
+}
+
+func TestYoutubeVideoMetadata_PublishAtPassed(t *testing.T) {
+	past := time.Now().Add(-time.Hour)
+	metadata := &YoutubeVideoMetadata{Title: "My video", PublishAt: &past}
+	metadata.SetDefaults()
+	assert.True(t, metadata.PublishAtPassed())
+	assert.Nil(t, metadata.ValidateLimits())
+
+	metadata.PublishNow()
+	assert.False(t, metadata.PublishAtPassed())
+	assert.Nil(t, metadata.Validate())
+	video := metadata.Video()
+	assert.Equal(t, "public", video.Status.PrivacyStatus)
+	assert.Empty(t, video.Status.PublishAt)
+}
@@ -0,0 +1,69 @@
+package domain
+
+import (
+	"fmt"
+
+	"google.golang.org/api/youtube/v3"
+)
+
+// - YoutubeVideoUpdatePayload changes an existing video, fields left out keep their current value
+type YoutubeVideoUpdatePayload struct {
+	Title         *string   `json:"title,omitempty"`
+	Description   *string   `json:"description,omitempty"`
+	Tags          *[]string `json:"tags,omitempty"`
+	CategoryId    *string   `json:"category_id,omitempty"`
+	PrivacyStatus *string   `json:"privacy_status,omitempty"`
+}
+
+func (p *YoutubeVideoUpdatePayload) Empty() bool {
+	return p.Title == nil && p.Description == nil && p.Tags == nil && p.CategoryId == nil && p.PrivacyStatus == nil
+}
+
+// - Apply merges the payload into the video read from youtube and checks the result with the upload rules.
+// - videos.update replaces the whole snippet and status parts, so both must come from videos.list.
+func (p *YoutubeVideoUpdatePayload) Apply(video *youtube.Video) error {
+	if video.Snippet == nil || video.Status == nil {
+		return fmt.Errorf("snippet and status of the video are required")
+	}
+
+	metadata := &YoutubeVideoMetadata{
+		Title:         video.Snippet.Title,
+		Description:   video.Snippet.Description,
+		Tags:          video.Snippet.Tags,
+		CategoryId:    video.Snippet.CategoryId,
+		PrivacyStatus: video.Status.PrivacyStatus,
+	}
+	if p.Title != nil {
+		metadata.Title = *p.Title
+	}
+	if p.Description != nil {
+		metadata.Description = *p.Description
+	}
+	if p.Tags != nil {
+		metadata.Tags = *p.Tags
+	}
+	if p.CategoryId != nil {
+		metadata.CategoryId = *p.CategoryId
+	}
+	if p.PrivacyStatus != nil {
+		metadata.PrivacyStatus = *p.PrivacyStatus
+	}
+	if err := metadata.Validate(); err != nil {
+		return err
+	}
+
+	video.Snippet.Title = metadata.Title
+	video.Snippet.Description = metadata.Description
+	video.Snippet.CategoryId = metadata.CategoryId
+	//- the API returns a 400 Bad Request response if tags is an empty string
+	video.Snippet.Tags = nil
+	if len(metadata.Tags) > 0 {
+		video.Snippet.Tags = metadata.Tags
+	}
+	video.Status.PrivacyStatus = metadata.PrivacyStatus
+	//- only private videos can be scheduled, making it public or unlisted publishes it now
+	if metadata.PrivacyStatus != "private" {
+		video.Status.PublishAt = ""
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/api/youtube/v3"
+)
+
+func currentVideo() *youtube.Video {
+	return &youtube.Video{
+		Id: "abc",
+		Snippet: &youtube.VideoSnippet{
+			Title:       "Old title",
+			Description: "Old description",
+			Tags:        []string{"go"},
+			CategoryId:  "22",
+		},
+		Status: &youtube.VideoStatus{
+			PrivacyStatus: "private",
+			PublishAt:     "2030-01-01T00:00:00Z",
+			License:       "creativeCommon",
+		},
+	}
+}
+
+func TestYoutubeVideoUpdatePayload_Apply(t *testing.T) {
+	title := "New title"
+	tags := []string{}
+	video := currentVideo()
+	err := (&YoutubeVideoUpdatePayload{Title: &title, Tags: &tags}).Apply(video)
+	assert.Nil(t, err)
+	assert.Equal(t, "New title", video.Snippet.Title)
+	assert.Equal(t, "Old description", video.Snippet.Description)
+	assert.Nil(t, video.Snippet.Tags)
+	assert.Equal(t, "22", video.Snippet.CategoryId)
+	//- untouched status fields are sent back as they were
+	assert.Equal(t, "2030-01-01T00:00:00Z", video.Status.PublishAt)
+	assert.Equal(t, "creativeCommon", video.Status.License)
+
+	public := "public"
+	video = currentVideo()
+	err = (&YoutubeVideoUpdatePayload{PrivacyStatus: &public}).Apply(video)
+	assert.Nil(t, err)
+	assert.Equal(t, "public", video.Status.PrivacyStatus)
+	assert.Equal(t, "", video.Status.PublishAt)
+
+	empty := " "
+	video = currentVideo()
+	err = (&YoutubeVideoUpdatePayload{Title: &empty}).Apply(video)
+	assert.EqualError(t, err, "title is required")
+	assert.Equal(t, "Old title", video.Snippet.Title)
+
+	hidden := "hidden"
+	err = (&YoutubeVideoUpdatePayload{PrivacyStatus: &hidden}).Apply(currentVideo())
+	assert.NotNil(t, err)
+}
+
+func TestYoutubeVideoUpdatePayload_Empty(t *testing.T) {
+	assert.True(t, (&YoutubeVideoUpdatePayload{}).Empty())
+	title := "title"
+	assert.False(t, (&YoutubeVideoUpdatePayload{Title: &title}).Empty())
+}
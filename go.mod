@@ -13,6 +13,7 @@ require (
 	github.com/stretchr/testify v1.8.3
 	golang.org/x/exp v0.0.0-20230905200255-921286631fa9
 	golang.org/x/oauth2 v0.12.0
+	golang.org/x/sync v0.2.0
 	google.golang.org/api v0.126.0
 )
 
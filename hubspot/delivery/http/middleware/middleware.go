@@ -63,6 +63,17 @@ func IsTokensValid(next http.Handler) http.Handler {
 			return
 		}
 
+		//- answered like the tiktok routes, the stored grant is no longer valid
+		if oauthInfo.ReconnectRequired {
+			w.WriteHeader(http.StatusUnauthorized)
+			render.JSON(w, r, domain.Response{
+				StatusCode: http.StatusUnauthorized,
+				Message:    http.StatusText(http.StatusUnauthorized),
+				Data:       "This account has to be reconnected",
+			})
+			return
+		}
+
 		accessToken := oauthInfo.AccessToken
 		appId := oauthInfo.AppId
 		subdomain := oauthInfo.Subdomain
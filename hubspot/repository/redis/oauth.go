@@ -5,9 +5,11 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"strconv"
 	"tiktok_api/app/logger"
 	"tiktok_api/domain"
 	"tiktok_api/domain/dbInstance"
+	"time"
 
 	"github.com/redis/go-redis/v9"
 )
@@ -16,6 +18,11 @@ var clientInstance = dbInstance.GetRedisInstance()
 var log = logger.NewLogrusLogger()
 var ctx = context.Background()
 
+const (
+	//- sorted set of record keys scored by access token expiry, read by the token refresher
+	TOKEN_EXPIRY_KEY = "hubspot_token_expiry"
+)
+
 func GetOneByTenantIdApiKeyType(tenantId string, apiKey string) (*domain.OAuth, error) {
 	key := fmt.Sprintf("%s-%s", tenantId, apiKey)
 	o := &domain.OAuth{}
@@ -98,6 +105,7 @@ func UpdateTenantDataBy(tenantId string, apiKey string, o *domain.OAuth) bool {
 		return false
 	}
 
+	indexTokenExpiry(key, o)
 	return true
 }
 
@@ -151,5 +159,83 @@ func UpdateTokensById(key string, o *domain.OAuth) bool {
 		return false
 	}
 
+	indexTokenExpiry(key, o)
 	return true
 }
+
+// - indexTokenExpiry keeps the refresher index in sync, only records that can still be refreshed are listed
+func indexTokenExpiry(key string, o *domain.OAuth) {
+	var err error
+	if !tokenIndexable(o) {
+		err = clientInstance.ZRem(ctx, TOKEN_EXPIRY_KEY, key).Err()
+	} else {
+		err = clientInstance.ZAdd(ctx, TOKEN_EXPIRY_KEY, redis.Z{
+			Score:  float64(o.ExpiresIn.Unix()),
+			Member: key,
+		}).Err()
+	}
+	if err != nil {
+		//- writing logs
+		log.Fields(logger.Fields{
+			"key":   key,
+			"error": err,
+		}).Errorf(err, "Error when index token expiry into redis")
+	}
+}
+
+func tokenIndexable(o *domain.OAuth) bool {
+	return o.RefreshToken != "" && !o.ReconnectRequired && !o.ExpiresIn.IsZero()
+}
+
+// - IndexAllTokenExpiries adds to the refresher index the records saved before it existed, indexed records are left as they are
+func IndexAllTokenExpiries() error {
+	//- records are stored at tenantId-apiKey
+	iter := clientInstance.ScanType(ctx, 0, "*-*", 100, "string").Iterator()
+	for iter.Next(ctx) {
+		key := iter.Val()
+		val, err := clientInstance.Get(ctx, key).Result()
+		if err != nil {
+			continue
+		}
+		o := &domain.OAuth{}
+		if err = json.Unmarshal([]byte(val), o); err != nil || !tokenIndexable(o) {
+			continue
+		}
+		err = clientInstance.ZAddNX(ctx, TOKEN_EXPIRY_KEY, redis.Z{
+			Score:  float64(o.ExpiresIn.Unix()),
+			Member: key,
+		}).Err()
+		if err != nil {
+			//- writing logs
+			log.Fields(logger.Fields{
+				"key":   key,
+				"error": err,
+			}).Errorf(err, "Error when index token expiry into redis")
+			return err
+		}
+	}
+	if err := iter.Err(); err != nil {
+		log.Fields(logger.Fields{
+			"error": err,
+		}).Errorf(err, "Error when scan hubspot records in redis")
+		return err
+	}
+	return nil
+}
+
+// - GetKeysExpiringBefore returns the record keys whose access token expires before the given time
+func GetKeysExpiringBefore(before time.Time) ([]string, error) {
+	keys, err := clientInstance.ZRangeByScore(ctx, TOKEN_EXPIRY_KEY, &redis.ZRangeBy{
+		Min: "-inf",
+		Max: strconv.FormatInt(before.Unix(), 10),
+	}).Result()
+	if err != nil {
+		//- writing logs
+		log.Fields(logger.Fields{
+			"key":   TOKEN_EXPIRY_KEY,
+			"error": err,
+		}).Errorf(err, "Error when ZRangeByScore from redis")
+		return nil, err
+	}
+	return keys, nil
+}
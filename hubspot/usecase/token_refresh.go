@@ -0,0 +1,67 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	utilhttp "tiktok_api/app/utils/http"
+	redisRepository "tiktok_api/hubspot/repository/redis"
+)
+
+// - RefreshExpiringTokensUseCase refreshes every hubspot access token expiring within the given window.
+// - Accounts whose refresh token is rejected by hubspot are marked as reconnect required.
+func RefreshExpiringTokensUseCase(within time.Duration) error {
+	keys, err := redisRepository.GetKeysExpiringBefore(time.Now().Add(within))
+	if err != nil {
+		return err
+	}
+
+	for _, key := range keys {
+		if err = refreshTokensById(key); err != nil {
+			handleError(err, fmt.Sprintf("Unable to refresh token of %s", key), "error")
+		}
+	}
+	return nil
+}
+
+// - IndexExistingTokensUseCase adds the accounts connected before the refresher index existed, run once before the first refresh
+func IndexExistingTokensUseCase() error {
+	return redisRepository.IndexAllTokenExpiries()
+}
+
+func refreshTokensById(key string) error {
+	oauthInfo, err := redisRepository.GetOneById(key)
+	if err != nil {
+		return err
+	}
+
+	_, err = SetAndUpdateAccessTokenUseCase(oauthInfo)
+	if err == nil {
+		return nil
+	}
+
+	//- only an explicit refusal of the refresh token means the grant is gone,
+	//- network errors, hubspot 5xx and rate limits or a failed save are retried on the next run
+	if !refreshTokenRejected(err) {
+		return err
+	}
+
+	oauthInfo.ReconnectRequired = true
+	if !redisRepository.UpdateTokensById(key, oauthInfo) {
+		return errors.New("Update token failed")
+	}
+	handleError(err, fmt.Sprintf("%s has to reconnect its hubspot account", key), "warn")
+	return nil
+}
+
+// - refreshTokenRejected tells whether hubspot answered the refresh with BAD_REFRESH_TOKEN or invalid_grant
+func refreshTokenRejected(err error) bool {
+	var customErr *utilhttp.CustomError
+	if errors.As(err, &customErr) {
+		return false
+	}
+	message := err.Error()
+	return strings.Contains(message, "BAD_REFRESH_TOKEN") || strings.Contains(message, "invalid_grant")
+}
@@ -0,0 +1,29 @@
+package usecase
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	utilhttp "tiktok_api/app/utils/http"
+)
+
+func TestRefreshTokenRejected(t *testing.T) {
+	cases := []struct {
+		name     string
+		err      error
+		expected bool
+	}{
+		{"bad refresh token", utilhttp.NewError(`{"status":"BAD_REFRESH_TOKEN","message":"missing or unknown refresh token"}`), true},
+		{"invalid grant", utilhttp.NewError(`{"error":"invalid_grant"}`), true},
+		{"server error", utilhttp.NewError(`{"status":"error","message":"internal error"}`), false},
+		{"network error", &utilhttp.CustomError{CustomMessage: "Error when creating response from client request", DefaultError: errors.New("invalid_grant")}, false},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			assert.Equal(t, c.expected, refreshTokenRejected(c.err))
+		})
+	}
+}
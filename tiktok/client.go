@@ -0,0 +1,67 @@
+package tiktok
+
+import (
+	"net/http"
+	"strings"
+	"time"
+)
+
+var (
+	defaultClient = NewClient(DefaultBaseURL, nil)
+)
+
+// Client calls the TikTok API on a configurable host with its own http client.
+type Client struct {
+	baseURL    string
+	httpClient *http.Client
+	version    APIVersion
+}
+
+// Option customizes a Client created with NewClient.
+type Option func(*Client)
+
+// WithAPIVersion selects the API generation used for OAuth and user info calls.
+// APIVersionV1 keeps tokens issued by the deprecated v1 API working, the base URL must then point at V1BaseURL.
+// Content posting and video calls only exist in the v2 API.
+func WithAPIVersion(version APIVersion) Option {
+	return func(c *Client) {
+		c.version = version
+	}
+}
+
+// NewClient returns a Client sending requests to baseURL through httpClient.
+// An empty baseURL falls back to DefaultBaseURL and a nil httpClient to a client with a 10 seconds timeout.
+// Clients use the v2 API unless WithAPIVersion says otherwise.
+func NewClient(baseURL string, httpClient *http.Client, opts ...Option) *Client {
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: time.Second * 10}
+	}
+
+	c := &Client{
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		httpClient: httpClient,
+		version:    APIVersionV2,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// BaseURL returns the host the client sends requests to.
+func (c *Client) BaseURL() string {
+	return c.baseURL
+}
+
+// Version returns the API generation used for OAuth and user info calls.
+func (c *Client) Version() APIVersion {
+	return c.version
+}
+
+func (c *Client) endpoint(path string) string {
+	return c.baseURL + path
+}
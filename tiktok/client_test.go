@@ -0,0 +1,215 @@
+package tiktok
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/oauth2"
+)
+
+// - newStubServer serves canned TikTok responses keyed by request path
+func newStubServer(t *testing.T, responses map[string]interface{}) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		response, ok := responses[r.URL.Path]
+		if !ok {
+			t.Errorf("unexpected request to %s", r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		json.NewEncoder(w).Encode(response)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestClient_ConfigExchangeV1(t *testing.T) {
+	server := newStubServer(t, map[string]interface{}{
+		pathTokenV1: map[string]interface{}{
+			"data": map[string]interface{}{
+				"open_id":            "open-id",
+				"scope":              "user.info.basic",
+				"access_token":       "access-token",
+				"expires_in":         86400,
+				"refresh_token":      "refresh-token",
+				"refresh_expires_in": 31536000,
+			},
+			"message": "success",
+		},
+	})
+	client := NewClient(server.URL, server.Client(), WithAPIVersion(APIVersionV1))
+
+	config, err := client.NewConfig("client-key", "client-secret", "http://localhost/callback")
+	assert.Nil(t, err)
+	assert.Equal(t, server.URL+pathAuthV1, config.Endpoint.AuthURL)
+
+	token, err := client.ConfigExchange(context.Background(), config, "code")
+	assert.Nil(t, err)
+	assert.Equal(t, "access-token", token.AccessToken)
+	assert.Equal(t, "refresh-token", token.RefreshToken)
+
+	openID, err := OpenIDFromToken(token)
+	assert.Nil(t, err)
+	assert.Equal(t, "open-id", openID)
+
+	refreshExpiresIn, err := RefreshExpiresInFromToken(token)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(31536000), refreshExpiresIn)
+}
+
+func TestClient_ConfigExchangeErrorV1(t *testing.T) {
+	server := newStubServer(t, map[string]interface{}{
+		pathTokenV1: map[string]interface{}{
+			"data": map[string]interface{}{
+				"description": "Authorization code is expired.",
+				"error_code":  10007,
+			},
+			"message": "error",
+		},
+	})
+	client := NewClient(server.URL, server.Client(), WithAPIVersion(APIVersionV1))
+	config, _ := client.NewConfig("client-key", "client-secret", "http://localhost/callback")
+
+	_, err := client.ConfigExchange(context.Background(), config, "code")
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "Authorization code is expired.")
+}
+
+func TestClient_RetrieveUserInfoAndRevokeV1(t *testing.T) {
+	server := newStubServer(t, map[string]interface{}{
+		pathUserInfoV1: map[string]interface{}{
+			"data": map[string]interface{}{
+				"open_id":      "open-id",
+				"union_id":     "union-id",
+				"display_name": "creator",
+			},
+		},
+		pathRevokeV1: map[string]interface{}{
+			"data":    map[string]interface{}{},
+			"message": "success",
+		},
+	})
+	client := NewClient(server.URL, server.Client(), WithAPIVersion(APIVersionV1))
+	token := (&oauth2.Token{AccessToken: "access-token"}).WithExtra(map[string]interface{}{"open_id": "open-id"})
+
+	userInfo, err := client.RetrieveUserInfo(context.Background(), token)
+	assert.Nil(t, err)
+	assert.Equal(t, "union-id", userInfo.UnionID)
+	assert.Equal(t, "creator", userInfo.DisplayName)
+
+	assert.Nil(t, client.RevokeAccess(context.Background(), nil, token))
+}
+
+func TestClient_ConfigExchangeAndRefresh(t *testing.T) {
+	var forms []url.Values
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, pathToken, r.URL.Path)
+		assert.Equal(t, "application/x-www-form-urlencoded", r.Header.Get("Content-Type"))
+		r.ParseForm()
+		forms = append(forms, r.PostForm)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"open_id":            "open-id",
+			"scope":              "user.info.basic,video.list",
+			"access_token":       "access-token",
+			"expires_in":         86400,
+			"refresh_token":      "refresh-token",
+			"refresh_expires_in": 31536000,
+			"token_type":         "Bearer",
+		})
+	}))
+	defer server.Close()
+	client := NewClient(server.URL, server.Client())
+
+	config, err := client.NewConfig("client-key", "client-secret", "http://localhost/callback")
+	assert.Nil(t, err)
+	assert.Equal(t, endpointAuth, config.Endpoint.AuthURL)
+
+	token, err := client.ConfigExchange(context.Background(), config, "code")
+	assert.Nil(t, err)
+	assert.Equal(t, "access-token", token.AccessToken)
+	scope, err := ScopeFromToken(token)
+	assert.Nil(t, err)
+	assert.Equal(t, "user.info.basic,video.list", scope)
+
+	_, err = client.RefreshToken(context.Background(), config, "refresh-token")
+	assert.Nil(t, err)
+
+	assert.Len(t, forms, 2)
+	assert.Equal(t, "authorization_code", forms[0].Get("grant_type"))
+	assert.Equal(t, "http://localhost/callback", forms[0].Get("redirect_uri"))
+	assert.Equal(t, "refresh_token", forms[1].Get("grant_type"))
+	assert.Equal(t, "client-secret", forms[1].Get("client_secret"))
+}
+
+func TestClient_ConfigExchangeError(t *testing.T) {
+	server := newStubServer(t, map[string]interface{}{
+		pathToken: map[string]interface{}{
+			"error":             "invalid_grant",
+			"error_description": "Authorization code is expired.",
+			"log_id":            "log-id",
+		},
+	})
+	client := NewClient(server.URL, server.Client())
+	config, _ := client.NewConfig("client-key", "client-secret", "http://localhost/callback")
+
+	_, err := client.ConfigExchange(context.Background(), config, "code")
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "invalid_grant")
+
+	var oauthErr *OAuthError
+	assert.True(t, errors.As(err, &oauthErr))
+	assert.Equal(t, "invalid_grant", oauthErr.Code)
+}
+
+func TestClient_RetrieveUserInfo(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, pathUserInfo, r.URL.Path)
+		assert.Equal(t, "Bearer access-token", r.Header.Get("Authorization"))
+		assert.Equal(t, "open_id,display_name,follower_count", r.URL.Query().Get("fields"))
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"data": map[string]interface{}{
+				"user": map[string]interface{}{
+					"open_id":        "open-id",
+					"display_name":   "creator",
+					"follower_count": 42,
+				},
+			},
+			"error": map[string]interface{}{"code": "ok"},
+		})
+	}))
+	defer server.Close()
+	client := NewClient(server.URL, server.Client())
+
+	userInfo, err := client.RetrieveUserInfo(context.Background(), &oauth2.Token{AccessToken: "access-token"}, "open_id", "display_name", "follower_count")
+	assert.Nil(t, err)
+	assert.Equal(t, "creator", userInfo.DisplayName)
+	assert.Equal(t, int64(42), userInfo.FollowerCount)
+}
+
+func TestClient_RevokeAccess(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, pathRevoke, r.URL.Path)
+		r.ParseForm()
+		assert.Equal(t, "access-token", r.PostForm.Get("token"))
+	}))
+	defer server.Close()
+	client := NewClient(server.URL, server.Client())
+	config, _ := client.NewConfig("client-key", "client-secret", "http://localhost/callback")
+
+	assert.Nil(t, client.RevokeAccess(context.Background(), config, &oauth2.Token{AccessToken: "access-token"}))
+}
+
+func TestNewClientDefaults(t *testing.T) {
+	client := NewClient("", nil)
+	assert.Equal(t, DefaultBaseURL, client.BaseURL())
+	assert.Equal(t, APIVersionV2, client.Version())
+
+	client = NewClient(V1BaseURL+"/", nil, WithAPIVersion(APIVersionV1))
+	assert.Equal(t, V1BaseURL, client.BaseURL())
+	assert.Equal(t, APIVersionV1, client.Version())
+}
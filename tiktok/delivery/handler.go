@@ -1,12 +1,31 @@
 package router
 
 import (
+	"encoding/json"
+	"errors"
 	"net/http"
+	"net/url"
+	"strconv"
+	"tiktok_api/app/logger"
+	"tiktok_api/app/pkg/httpErrors"
 	"tiktok_api/domain"
+	"tiktok_api/tiktok"
+	"tiktok_api/tiktok/repository/redis"
+	tiktokUsecase "tiktok_api/tiktok/usecase"
 
+	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/render"
 )
 
+var log = logger.NewLogrusLogger()
+
+const (
+	MB = 1 << 20 //- 1MB
+
+	//- privacy level allowed for unaudited tiktok clients
+	defaultPrivacyLevel = "SELF_ONLY"
+)
+
 func TiktokAPISampleCall(w http.ResponseWriter, r *http.Request) error {
 	//- Call logic from use case or repository
 	//- call to another service
@@ -19,3 +38,206 @@ func TiktokAPISampleCall(w http.ResponseWriter, r *http.Request) error {
 
 	return nil
 }
+
+// - generate AuthURL from config file
+func GenerateAuthURL(w http.ResponseWriter, r *http.Request) error {
+	authURL, clientKey, err := tiktokUsecase.GetAuthURL()
+	if err != nil {
+		return httpErrors.NewInternalServerError(err.Error())
+	}
+	render.JSON(w, r, domain.Response{
+		Message: "Success",
+		Data: map[string]string{
+			"client_key":      clientKey,
+			"tiktok_auth_url": authURL,
+		},
+		StatusCode: 200,
+	})
+	return nil
+}
+
+func OAuthTiktokCallback(w http.ResponseWriter, r *http.Request) {
+	//- get info from database by using state - client key
+	clientKey := r.FormValue("state")
+	//- Get responsed code
+	code := r.FormValue("code")
+	url := tiktokUsecase.TiktokOAuthCodeExchange(clientKey, code)
+
+	http.Redirect(w, r, url, http.StatusMovedPermanently)
+}
+
+func RevokeAccess(w http.ResponseWriter, r *http.Request) error {
+	var key domain.TiktokOAuthKey
+	err := json.NewDecoder(r.Body).Decode(&key)
+	if err != nil {
+		return httpErrors.NewBadRequestError(err.Error())
+	}
+
+	err = tiktokUsecase.RevokeAccess(key.ClientKey)
+	if err != nil {
+		return toHttpError(err)
+	}
+
+	render.JSON(w, r, domain.Response{
+		Message: "Success",
+		Data: map[string]string{
+			"client_key": key.ClientKey,
+		},
+		StatusCode: 200,
+	})
+	return nil
+}
+
+func TiktokUserInfo(w http.ResponseWriter, r *http.Request) error {
+	clientKey := chi.URLParam(r, "clientKey")
+	userInfo, err := tiktokUsecase.TiktokUserInfo(clientKey)
+	if err != nil {
+		return toHttpError(err)
+	}
+
+	render.JSON(w, r, domain.Response{
+		Message:    "Success",
+		Data:       userInfo,
+		StatusCode: 200,
+	})
+	return nil
+}
+
+// - using form, the video part is streamed to a temp file instead of memory
+func TiktokVideoUploadFile(w http.ResponseWriter, r *http.Request) error {
+	upload, err := receiveVideoUpload(w, r, "file_upload")
+	if err != nil {
+		fields := logger.Fields{
+			"service": "Tiktok",
+			"message": "Error when receive multipart upload",
+		}
+		log.Fields(fields).Errorf(err, "Error when receive multipart upload")
+		return err
+	}
+	defer upload.Remove()
+
+	clientKey := upload.Form.Get("client_key")
+	postInfo := tiktok.PostInfo{
+		Title:          upload.Form.Get("title"),
+		PrivacyLevel:   upload.Form.Get("privacy_level"),
+		DisableDuet:    formBool(upload.Form, "disable_duet"),
+		DisableComment: formBool(upload.Form, "disable_comment"),
+		DisableStitch:  formBool(upload.Form, "disable_stitch"),
+	}
+	if postInfo.PrivacyLevel == "" {
+		postInfo.PrivacyLevel = defaultPrivacyLevel
+	}
+
+	uploadInfo, err := tiktokUsecase.TiktokVideoUploadFile(upload.File, clientKey, postInfo, upload.Info)
+	if err != nil {
+		fields := logger.Fields{
+			"service": "Tiktok",
+			"message": "Error when Video upload failed",
+		}
+		log.Fields(fields).Errorf(err, "Error when Video upload failed")
+		return toHttpError(err)
+	}
+
+	render.JSON(w, r, domain.Response{
+		Message:    "Video upload success",
+		Data:       uploadInfo,
+		StatusCode: 200,
+	})
+	return nil
+}
+
+func TiktokVideoPublishStatus(w http.ResponseWriter, r *http.Request) error {
+	clientKey := chi.URLParam(r, "clientKey")
+	publishId := chi.URLParam(r, "publishId")
+	uploadInfo, err := tiktokUsecase.TiktokVideoPublishStatus(clientKey, publishId)
+	if err != nil {
+		return toHttpError(err)
+	}
+
+	render.JSON(w, r, domain.Response{
+		Message:    "Success",
+		Data:       uploadInfo,
+		StatusCode: 200,
+	})
+	return nil
+}
+
+func TiktokVideoList(w http.ResponseWriter, r *http.Request) error {
+	clientKey := chi.URLParam(r, "clientKey")
+
+	var cursor int64
+	var maxCount int
+	var err error
+	if value := r.URL.Query().Get("cursor"); value != "" {
+		if cursor, err = strconv.ParseInt(value, 10, 64); err != nil {
+			return httpErrors.NewBadRequestError("cursor must be a number")
+		}
+	}
+	if value := r.URL.Query().Get("max_count"); value != "" {
+		if maxCount, err = strconv.Atoi(value); err != nil {
+			return httpErrors.NewBadRequestError("max_count must be a number")
+		}
+	}
+
+	videoList, err := tiktokUsecase.TiktokVideoList(clientKey, cursor, maxCount)
+	if err != nil {
+		return toHttpError(err)
+	}
+
+	render.JSON(w, r, domain.Response{
+		Message:    "Success",
+		Data:       videoList,
+		StatusCode: 200,
+	})
+	return nil
+}
+
+func TiktokVideoEngagement(w http.ResponseWriter, r *http.Request) error {
+	clientKey := chi.URLParam(r, "clientKey")
+	videoId := chi.URLParam(r, "videoId")
+	video, err := tiktokUsecase.TiktokVideoEngagement(clientKey, videoId)
+	if err != nil {
+		return toHttpError(err)
+	}
+
+	render.JSON(w, r, domain.Response{
+		Message:    "Success",
+		Data:       video,
+		StatusCode: 200,
+	})
+	return nil
+}
+
+func formBool(form url.Values, key string) bool {
+	value, _ := strconv.ParseBool(form.Get(key))
+	return value
+}
+
+// - unknown or not yet connected client keys are reported as 404, accounts to reconnect as 401
+// - and requests tiktok cannot take as 400. Client errors of tiktok keep their status, its own failures
+// - and unreachable tiktok servers are a bad gateway, anything else is a 500.
+func toHttpError(err error) error {
+	if errors.Is(err, redis.ErrClientKeyNotFound) || errors.Is(err, tiktokUsecase.ErrNotConnected) || errors.Is(err, redis.ErrUploadNotFound) || errors.Is(err, tiktokUsecase.ErrVideoNotFound) {
+		return httpErrors.NewNotFoundError(err.Error())
+	}
+	if errors.Is(err, tiktokUsecase.ErrReconnectRequired) {
+		return httpErrors.NewUnauthorizedError(err.Error())
+	}
+	if errors.Is(err, tiktokUsecase.ErrLegacyAPIVersion) || errors.Is(err, tiktokUsecase.ErrVideoRejected) {
+		return httpErrors.NewBadRequestError(err.Error())
+	}
+
+	var apiErr *tiktok.APIError
+	if errors.As(err, &apiErr) {
+		if apiErr.StatusCode >= http.StatusBadRequest && apiErr.StatusCode < http.StatusInternalServerError {
+			return httpErrors.NewRestError(apiErr.StatusCode, http.StatusText(apiErr.StatusCode), apiErr.Error())
+		}
+		return httpErrors.NewRestError(http.StatusBadGateway, http.StatusText(http.StatusBadGateway), apiErr.Error())
+	}
+	var oauthErr *tiktok.OAuthError
+	var urlErr *url.Error
+	if errors.As(err, &oauthErr) || errors.As(err, &urlErr) {
+		return httpErrors.NewRestError(http.StatusBadGateway, http.StatusText(http.StatusBadGateway), err.Error())
+	}
+	return httpErrors.NewInternalServerError(err.Error())
+}
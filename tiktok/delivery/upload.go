@@ -0,0 +1,140 @@
+package router
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"os"
+	"tiktok_api/app/pkg/httpErrors"
+	"tiktok_api/app/utils"
+	"tiktok_api/domain"
+	"time"
+
+	"github.com/spf13/viper"
+	"golang.org/x/exp/slices"
+)
+
+const (
+	defaultUploadMaxSize = 64 * MB
+	//- plain form fields never need more than this
+	maxFormFieldSize = 64 << 10
+)
+
+// - videoUpload is an upload form whose video part has been streamed to a temp file
+type videoUpload struct {
+	Form url.Values
+	File *os.File
+	Info *domain.TiktokFileUploadInfo
+}
+
+// - Remove closes and deletes the temp file, safe to call more than once
+func (u *videoUpload) Remove() {
+	if u.File != nil {
+		u.File.Close()
+		os.Remove(u.File.Name())
+	}
+	u.File = nil
+}
+
+func uploadMaxSize() int64 {
+	if size := viper.GetInt64("TIKTOK.UPLOAD_MAX_SIZE_MB"); size > 0 {
+		return size * MB
+	}
+	return defaultUploadMaxSize
+}
+
+// - receiveVideoUpload streams the request part by part so the video is never held in memory.
+// - The video part named fileField is written to a temp file, every other part is kept as a form value.
+func receiveVideoUpload(w http.ResponseWriter, r *http.Request, fileField string) (*videoUpload, error) {
+	maxSize := uploadMaxSize()
+	//- leave some room for the form fields around the video
+	r.Body = http.MaxBytesReader(w, r.Body, maxSize+MB)
+
+	reader, err := r.MultipartReader()
+	if err != nil {
+		return nil, httpErrors.NewBadRequestError("request must be multipart/form-data")
+	}
+
+	upload := &videoUpload{Form: url.Values{}}
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			upload.Remove()
+			return nil, uploadError(err, maxSize)
+		}
+
+		if part.FormName() == fileField && part.FileName() != "" {
+			if upload.File != nil {
+				upload.Remove()
+				return nil, httpErrors.NewBadRequestError(fmt.Sprintf("only one %s is allowed", fileField))
+			}
+			if err = upload.saveFile(part, maxSize); err != nil {
+				upload.Remove()
+				return nil, uploadError(err, maxSize)
+			}
+			continue
+		}
+
+		value, err := io.ReadAll(io.LimitReader(part, maxFormFieldSize))
+		if err != nil {
+			upload.Remove()
+			return nil, uploadError(err, maxSize)
+		}
+		upload.Form.Add(part.FormName(), string(value))
+	}
+
+	if upload.File == nil {
+		return nil, httpErrors.NewBadRequestError(fmt.Sprintf("%s is required", fileField))
+	}
+	return upload, nil
+}
+
+func (u *videoUpload) saveFile(part *multipart.Part, maxSize int64) error {
+	contentType := part.Header.Get("Content-Type")
+	if !slices.Contains(utils.VideoContentType, contentType) {
+		return httpErrors.NewBadRequestError("invalid media type, the file is not a video")
+	}
+
+	file, err := os.CreateTemp(viper.GetString("TIKTOK.UPLOAD_TMP_DIR"), "tiktok-upload-*")
+	if err != nil {
+		return httpErrors.NewInternalServerError(err.Error())
+	}
+	u.File = file
+
+	size, err := io.Copy(file, io.LimitReader(part, maxSize+1))
+	if err != nil {
+		return err
+	}
+	if size > maxSize {
+		return &http.MaxBytesError{Limit: maxSize}
+	}
+	if size == 0 {
+		return httpErrors.NewBadRequestError("video is empty")
+	}
+
+	u.Info = &domain.TiktokFileUploadInfo{
+		FileName:        part.FileName(),
+		FileSize:        size,
+		FileContentType: contentType,
+		CreatedAt:       time.Now(),
+	}
+	return nil
+}
+
+func uploadError(err error, maxSize int64) error {
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		return httpErrors.NewRequestTooLargeError(fmt.Sprintf("video must be at most %d MB", maxSize/MB))
+	}
+	var restErr httpErrors.Error
+	if errors.As(err, &restErr) {
+		return restErr
+	}
+	return httpErrors.NewBadRequestError(err.Error())
+}
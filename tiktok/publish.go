@@ -0,0 +1,214 @@
+package tiktok
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+
+	"golang.org/x/oauth2"
+)
+
+const (
+	// PublishStatusComplete is reported once the post is live on the creator's profile.
+	PublishStatusComplete = "PUBLISH_COMPLETE"
+	// PublishStatusFailed is reported when TikTok rejected the post, see PublishStatus.FailReason.
+	PublishStatusFailed = "FAILED"
+
+	minChunkSize = 5 << 20  // 5MB
+	maxChunkSize = 64 << 20 // 64MB
+)
+
+// ChunkPlan returns the chunk size and chunk count TikTok expects for a video of the given size.
+// Videos smaller than 5MB are sent as a single chunk and the last chunk absorbs any remainder.
+func ChunkPlan(videoSize, chunkSize int64) (int64, int64) {
+	if chunkSize < minChunkSize {
+		chunkSize = minChunkSize
+	}
+	if chunkSize > maxChunkSize {
+		chunkSize = maxChunkSize
+	}
+	if videoSize <= chunkSize {
+		return videoSize, 1
+	}
+	return chunkSize, videoSize / chunkSize
+}
+
+// InitVideoUpload initializes a direct post of a local video file and returns the upload session.
+func InitVideoUpload(ctx context.Context, token *oauth2.Token, postInfo PostInfo, videoSize, chunkSize int64) (*UploadSession, error) {
+	return defaultClient.InitVideoUpload(ctx, token, postInfo, videoSize, chunkSize)
+}
+
+// UploadVideo pushes the video bytes to the upload url of the session, one chunk per request.
+func UploadVideo(ctx context.Context, session *UploadSession, video io.ReaderAt, contentType string) error {
+	return defaultClient.UploadVideo(ctx, session, video, contentType)
+}
+
+// FetchPublishStatus returns the current status of a post created with InitVideoUpload.
+func FetchPublishStatus(ctx context.Context, token *oauth2.Token, publishID string) (*PublishStatus, error) {
+	return defaultClient.FetchPublishStatus(ctx, token, publishID)
+}
+
+// InitVideoUpload initializes a direct post of a local video file and returns the upload session.
+func (c *Client) InitVideoUpload(ctx context.Context, token *oauth2.Token, postInfo PostInfo, videoSize, chunkSize int64) (*UploadSession, error) {
+	if token == nil || token.AccessToken == "" {
+		return nil, fmt.Errorf("tiktok-oauth2: InitVideoUpload: token cannot be empty")
+	}
+
+	if videoSize <= 0 {
+		return nil, fmt.Errorf("tiktok-oauth2: InitVideoUpload: video size must be positive")
+	}
+
+	chunkSize, totalChunkCount := ChunkPlan(videoSize, chunkSize)
+	payload := publishInitRequest{
+		PostInfo: postInfo,
+		SourceInfo: sourceInfo{
+			Source:          "FILE_UPLOAD",
+			VideoSize:       videoSize,
+			ChunkSize:       chunkSize,
+			TotalChunkCount: totalChunkCount,
+		},
+	}
+
+	var body publishInitResponse
+	if err := c.postJSON(ctx, pathPublishVideoInit, token, payload, &body); err != nil {
+		return nil, fmt.Errorf("tiktok-oauth2: InitVideoUpload: %w", err)
+	}
+
+	if body.Data.PublishID == "" || body.Data.UploadURL == "" {
+		return nil, fmt.Errorf("tiktok-oauth2: InitVideoUpload: server response missing publish_id or upload_url")
+	}
+
+	return &UploadSession{
+		PublishID:       body.Data.PublishID,
+		UploadURL:       body.Data.UploadURL,
+		VideoSize:       videoSize,
+		ChunkSize:       chunkSize,
+		TotalChunkCount: totalChunkCount,
+	}, nil
+}
+
+// UploadVideo pushes the video bytes to the upload url of the session, one chunk per request.
+func (c *Client) UploadVideo(ctx context.Context, session *UploadSession, video io.ReaderAt, contentType string) error {
+	if session == nil {
+		return fmt.Errorf("tiktok-oauth2: UploadVideo: session cannot be nil")
+	}
+
+	for chunk := int64(0); chunk < session.TotalChunkCount; chunk++ {
+		start := chunk * session.ChunkSize
+		end := start + session.ChunkSize - 1
+		//- last chunk takes whatever is left
+		if chunk == session.TotalChunkCount-1 {
+			end = session.VideoSize - 1
+		}
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodPut, session.UploadURL, io.NewSectionReader(video, start, end-start+1))
+		if err != nil {
+			return fmt.Errorf("tiktok-oauth2: UploadVideo: %w", err)
+		}
+		req.ContentLength = end - start + 1
+		req.Header.Set("Content-Type", contentType)
+		req.Header.Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", start, end, session.VideoSize))
+
+		response, err := c.httpClient.Do(req)
+		if err != nil {
+			return fmt.Errorf("tiktok-oauth2: UploadVideo: %w", err)
+		}
+
+		bodyBytes, err := ioutil.ReadAll(response.Body)
+		response.Body.Close()
+		if err != nil {
+			return fmt.Errorf("tiktok-oauth2: UploadVideo: %w", err)
+		}
+
+		if response.StatusCode != http.StatusPartialContent && response.StatusCode != http.StatusCreated && response.StatusCode != http.StatusOK {
+			return fmt.Errorf("tiktok-oauth2: UploadVideo: chunk %d failed: %w", chunk, &APIError{StatusCode: response.StatusCode, Message: string(bodyBytes)})
+		}
+	}
+
+	return nil
+}
+
+// FetchPublishStatus returns the current status of a post created with InitVideoUpload.
+func (c *Client) FetchPublishStatus(ctx context.Context, token *oauth2.Token, publishID string) (*PublishStatus, error) {
+	if token == nil || token.AccessToken == "" {
+		return nil, fmt.Errorf("tiktok-oauth2: FetchPublishStatus: token cannot be empty")
+	}
+
+	if publishID == "" {
+		return nil, fmt.Errorf("tiktok-oauth2: FetchPublishStatus: publish id cannot be empty")
+	}
+
+	var body publishStatusResponse
+	payload := map[string]string{"publish_id": publishID}
+	if err := c.postJSON(ctx, pathPublishStatusFetch, token, payload, &body); err != nil {
+		return nil, fmt.Errorf("tiktok-oauth2: FetchPublishStatus: %w", err)
+	}
+
+	status := &PublishStatus{
+		Status:        body.Data.Status,
+		FailReason:    body.Data.FailReason,
+		UploadedBytes: body.Data.UploadedBytes,
+	}
+	for _, postID := range body.Data.PubliclyAvailablePostIDs {
+		status.PostIDs = append(status.PostIDs, postID.String())
+	}
+
+	return status, nil
+}
+
+// postJSON sends an authorized JSON request to a v2 endpoint and decodes the response into out.
+func (c *Client) postJSON(ctx context.Context, path string, token *oauth2.Token, payload interface{}, out interface{}) error {
+	payloadBytes, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.endpoint(path), bytes.NewReader(payloadBytes))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+token.AccessToken)
+	req.Header.Set("Content-Type", "application/json; charset=UTF-8")
+
+	response, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+
+	defer response.Body.Close()
+
+	bodyBytes, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return err
+	}
+
+	if err = handleAPIError(response.StatusCode, bodyBytes); err != nil {
+		return err
+	}
+
+	return json.Unmarshal(bodyBytes, out)
+}
+
+// handleAPIError returns the error carried in a v2 response body, if any.
+func handleAPIError(statusCode int, data []byte) error {
+	var body struct {
+		Error apiError `json:"error"`
+	}
+	if err := json.Unmarshal(data, &body); err != nil {
+		//- gateways in front of tiktok answer failures without a json body
+		if statusCode >= http.StatusBadRequest {
+			return &APIError{StatusCode: statusCode, Message: string(data)}
+		}
+		return err
+	}
+
+	if body.Error.Code != "" && body.Error.Code != "ok" {
+		return &APIError{StatusCode: statusCode, Code: body.Error.Code, Message: body.Error.Message, LogID: body.Error.LogID}
+	}
+
+	return nil
+}
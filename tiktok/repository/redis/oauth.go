@@ -0,0 +1,206 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand"
+	"strconv"
+	"tiktok_api/app/logger"
+	"tiktok_api/domain"
+	"tiktok_api/domain/dbInstance"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+var clientInstance = dbInstance.GetRedisInstance()
+var log = logger.NewLogrusLogger()
+var ctx = context.Background()
+
+const (
+	KEY_PREFIX = "tiktok"
+	//- sorted set of client keys scored by access token expiry, read by the token refresher
+	TOKEN_EXPIRY_KEY = "tiktok_token_expiry"
+)
+
+const charset = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+
+var ErrClientKeyNotFound = errors.New("tiktok client key not found")
+
+func generateRandomString(length int) string {
+	b := make([]byte, length)
+	randomeKey := rand.New(rand.NewSource(time.Now().UnixNano()))
+	for i := range b {
+		b[i] = charset[randomeKey.Intn(len(charset))]
+	}
+	return string(b)
+}
+
+// - tiktok client keys are namespaced so they never collide with youtube and hubspot keys
+func oauthKey(clientKey string) string {
+	return fmt.Sprintf("%s_%s", KEY_PREFIX, clientKey)
+}
+
+func CreateNewTiktokClient(apiVersion string) (string, error) {
+	clientKey := generateRandomString(12) //- same with length of objectId, 12 bytes
+	tOAuth := &domain.TiktokOAuth{
+		ClientKey:  clientKey,
+		APIVersion: apiVersion,
+		CreatedAt:  time.Now(),
+	}
+	tOAuthByte, err := json.Marshal(&tOAuth)
+	if err != nil {
+		handleError(err, fmt.Sprintf("Error when json.Marshal into redis at key %s", clientKey), "error")
+		return "", err
+	}
+
+	err = clientInstance.Set(ctx, oauthKey(clientKey), string(tOAuthByte), 0).Err()
+	if err != nil {
+		//- writing logs and error handling
+		handleError(err, fmt.Sprintf("Error when set into redis at key %s", clientKey), "error")
+		return "", err
+	}
+	return clientKey, nil
+}
+
+func IsExist(clientKey string) bool {
+	count, err := clientInstance.Exists(ctx, oauthKey(clientKey)).Result()
+	return err == nil && count > 0
+}
+
+func GetClientByClientKey(clientKey string) (*domain.TiktokOAuth, error) {
+	val, err := clientInstance.Get(ctx, oauthKey(clientKey)).Result()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, ErrClientKeyNotFound
+		}
+		//- writing logs and error handling
+		handleError(err, fmt.Sprintf("Error when get key %s from redis", clientKey), "error")
+		return nil, err
+	}
+
+	tOAuth := &domain.TiktokOAuth{}
+	err = json.Unmarshal([]byte(val), &tOAuth)
+	if err != nil {
+		//- writing logs and error handling
+		handleError(err, fmt.Sprintf("Value of key %s failed to parse to json", clientKey), "error")
+		return nil, err
+	}
+	return tOAuth, nil
+}
+
+func UpdateTiktokByClientKey(clientKey string, tiktokOAuth *domain.TiktokOAuth) bool {
+	tiktokOAuth.UpdatedAt = time.Now()
+	byte, err := json.Marshal(&tiktokOAuth)
+	if err != nil {
+		//- writing logs and error handling
+		handleError(err, fmt.Sprintf("Error when json.Marshal into redis at key %s", clientKey), "error")
+		return false
+	}
+
+	err = clientInstance.Set(ctx, oauthKey(clientKey), string(byte), 0).Err()
+	if err != nil {
+		//- writing logs and error handling
+		handleError(err, fmt.Sprintf("Error when update value at key %s", clientKey), "error")
+		return false
+	}
+
+	indexTokenExpiry(clientKey, tiktokOAuth)
+	return true
+}
+
+// - indexTokenExpiry keeps the refresher index in sync, only records that can still be refreshed are listed
+func indexTokenExpiry(clientKey string, tiktokOAuth *domain.TiktokOAuth) {
+	var err error
+	if !tokenIndexable(tiktokOAuth) {
+		err = clientInstance.ZRem(ctx, TOKEN_EXPIRY_KEY, clientKey).Err()
+	} else {
+		err = clientInstance.ZAdd(ctx, TOKEN_EXPIRY_KEY, redis.Z{
+			Score:  float64(tiktokOAuth.Expiry.Unix()),
+			Member: clientKey,
+		}).Err()
+	}
+	if err != nil {
+		handleError(err, fmt.Sprintf("Error when index token expiry of key %s", clientKey), "error")
+	}
+}
+
+func tokenIndexable(tiktokOAuth *domain.TiktokOAuth) bool {
+	return tiktokOAuth.RefreshToken != "" && !tiktokOAuth.ReconnectRequired && !tiktokOAuth.Expiry.IsZero()
+}
+
+// - IndexAllTokenExpiries adds to the refresher index the records saved before it existed, indexed records are left as they are
+func IndexAllTokenExpiries() error {
+	iter := clientInstance.ScanType(ctx, 0, oauthKey("*"), 100, "string").Iterator()
+	for iter.Next(ctx) {
+		key := iter.Val()
+		val, err := clientInstance.Get(ctx, key).Result()
+		if err != nil {
+			continue
+		}
+		tiktokOAuth := &domain.TiktokOAuth{}
+		if err = json.Unmarshal([]byte(val), tiktokOAuth); err != nil || tiktokOAuth.ClientKey == "" || !tokenIndexable(tiktokOAuth) {
+			continue
+		}
+		err = clientInstance.ZAddNX(ctx, TOKEN_EXPIRY_KEY, redis.Z{
+			Score:  float64(tiktokOAuth.Expiry.Unix()),
+			Member: tiktokOAuth.ClientKey,
+		}).Err()
+		if err != nil {
+			handleError(err, fmt.Sprintf("Error when index token expiry of key %s", tiktokOAuth.ClientKey), "error")
+			return err
+		}
+	}
+	if err := iter.Err(); err != nil {
+		handleError(err, "Error when scan tiktok clients", "error")
+		return err
+	}
+	return nil
+}
+
+// - GetClientKeysExpiringBefore returns the client keys whose access token expires before the given time
+func GetClientKeysExpiringBefore(before time.Time) ([]string, error) {
+	clientKeys, err := clientInstance.ZRangeByScore(ctx, TOKEN_EXPIRY_KEY, &redis.ZRangeBy{
+		Min: "-inf",
+		Max: strconv.FormatInt(before.Unix(), 10),
+	}).Result()
+	if err != nil {
+		handleError(err, fmt.Sprintf("Error when read %s from redis", TOKEN_EXPIRY_KEY), "error")
+		return nil, err
+	}
+	return clientKeys, nil
+}
+
+func DeleteTiktokByClientKey(clientKey string) error {
+	_, err := clientInstance.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Del(ctx, oauthKey(clientKey))
+		pipe.ZRem(ctx, TOKEN_EXPIRY_KEY, clientKey)
+		return nil
+	})
+	if err != nil {
+		handleError(err, fmt.Sprintf("Error when delete key %s from redis", clientKey), "error")
+		return err
+	}
+	return nil
+}
+
+func handleError(err error, message string, errorType string) {
+	fields := logger.Fields{
+		"service": "Tiktok",
+		"message": message,
+	}
+	switch errorType {
+	case "fatal":
+		log.Fields(fields).Fatalf(err, message)
+	case "error":
+		log.Fields(fields).Errorf(err, message)
+	case "warn":
+		log.Fields(fields).Warnf(message)
+	case "info":
+		log.Fields(fields).Infof(message)
+	case "debug":
+		log.Fields(fields).Debugf(message)
+	}
+}
@@ -1 +1,66 @@
 package redis
+
+import (
+	"encoding/json"
+	"fmt"
+	"tiktok_api/tiktok"
+	"time"
+)
+
+const (
+	USER_INFO_EXPIRATION = 24 * time.Hour
+)
+
+func userInfoKey(clientKey string) string {
+	return fmt.Sprintf("%s_%s_user", KEY_PREFIX, clientKey)
+}
+
+func SaveUserInfo(clientKey string, userInfo *tiktok.UserInfo) (bool, error) {
+	byte, err := json.Marshal(&userInfo)
+	if err != nil {
+		//- writing logs and error handling
+		handleError(err, fmt.Sprintf("Error when json.Marshal into redis at key %s", clientKey), "error")
+		return false, err
+	}
+
+	err = clientInstance.Set(ctx, userInfoKey(clientKey), string(byte), USER_INFO_EXPIRATION).Err()
+	if err != nil {
+		handleError(err, "Error when set user info into redis", "error")
+		return false, err
+	}
+	return true, nil
+}
+
+func GetUserInfo(clientKey string) (*tiktok.UserInfo, error) {
+	val, err := clientInstance.Get(ctx, userInfoKey(clientKey)).Result()
+	if err != nil {
+		handleError(err, "Error when get user info from redis", "error")
+		return nil, err
+	}
+
+	userInfo := &tiktok.UserInfo{}
+	err = json.Unmarshal([]byte(val), &userInfo)
+	if err != nil {
+		handleError(err, "Error when unmarshal user info from redis", "error")
+		return nil, err
+	}
+	return userInfo, nil
+}
+
+func IsUserInfoExist(clientKey string) (bool, error) {
+	isExist, err := clientInstance.Exists(ctx, userInfoKey(clientKey)).Result()
+	if err != nil {
+		handleError(err, "Error when check user info from redis", "error")
+		return false, err
+	}
+	return isExist > 0, nil
+}
+
+func DeleteUserInfo(clientKey string) error {
+	err := clientInstance.Del(ctx, userInfoKey(clientKey)).Err()
+	if err != nil {
+		handleError(err, fmt.Sprintf("Error when delete user info of key %s from redis", clientKey), "error")
+		return err
+	}
+	return nil
+}
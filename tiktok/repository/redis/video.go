@@ -0,0 +1,103 @@
+package redis
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"tiktok_api/domain"
+	"tiktok_api/tiktok"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	HSET_KEY = "tiktok"
+)
+
+var ErrUploadNotFound = errors.New("tiktok upload not found")
+
+func publishField(clientKey string, publishId string) string {
+	return fmt.Sprintf("%s_%s", clientKey, publishId)
+}
+
+func SaveTiktokFileUploadInfo(clientKey string, tiktokFileUploadInfo *domain.TiktokFileUploadInfo) (bool, error) {
+	byte, err := json.Marshal(&tiktokFileUploadInfo)
+	if err != nil {
+		//- writing logs and error handling
+		handleError(err, fmt.Sprintf("Error when json.Marshal into redis at key %s", clientKey), "error")
+		return false, err
+	}
+
+	err = clientInstance.HSet(ctx, HSET_KEY, publishField(clientKey, tiktokFileUploadInfo.PublishId), string(byte)).Err()
+	if err != nil {
+		handleError(err, "Error when save tiktok file upload info into redis", "error")
+		return false, err
+	}
+	return true, nil
+}
+
+func GetTiktokFileUploadInfo(clientKey string, publishId string) (*domain.TiktokFileUploadInfo, error) {
+	val, err := clientInstance.HGet(ctx, HSET_KEY, publishField(clientKey, publishId)).Result()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, ErrUploadNotFound
+		}
+		handleError(err, "Error when get tiktok file upload info from redis", "error")
+		return nil, err
+	}
+
+	tiktokFileUploadInfo := &domain.TiktokFileUploadInfo{}
+	err = json.Unmarshal([]byte(val), &tiktokFileUploadInfo)
+	if err != nil {
+		handleError(err, "Error when unmarshal tiktok file upload info from redis", "error")
+		return nil, err
+	}
+	return tiktokFileUploadInfo, nil
+}
+
+func videoKey(clientKey string, videoId string) string {
+	return fmt.Sprintf("%s_%s_%s", KEY_PREFIX, clientKey, videoId)
+}
+
+func SaveVideoEngagementInfo(clientKey string, video *tiktok.Video) (bool, error) {
+	byte, err := json.Marshal(&video)
+	if err != nil {
+		//- writing logs and error handling
+		handleError(err, fmt.Sprintf("Error when json.Marshal into redis at key %s", clientKey), "error")
+		return false, err
+	}
+
+	expirationHour := 24 * time.Hour
+	err = clientInstance.Set(ctx, videoKey(clientKey, video.ID), string(byte), expirationHour).Err()
+	if err != nil {
+		handleError(err, "Error when set TTL info into redis", "error")
+		return false, err
+	}
+	return true, nil
+}
+
+func GetVideoEngagementInfo(clientKey string, videoId string) (*tiktok.Video, error) {
+	val, err := clientInstance.Get(ctx, videoKey(clientKey, videoId)).Result()
+	if err != nil {
+		handleError(err, "Error when get video engagement from redis", "error")
+		return nil, err
+	}
+
+	video := &tiktok.Video{}
+	err = json.Unmarshal([]byte(val), &video)
+	if err != nil {
+		handleError(err, "Error when unmarshal video engagement info from redis", "error")
+		return nil, err
+	}
+	return video, nil
+}
+
+func IsVideoEngagementExist(clientKey string, videoId string) (bool, error) {
+	isExist, err := clientInstance.Exists(ctx, videoKey(clientKey, videoId)).Result()
+	if err != nil {
+		handleError(err, "Error when check video engagement from redis", "error")
+		return false, err
+	}
+	return isExist > 0, nil
+}
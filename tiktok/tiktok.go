@@ -6,17 +6,40 @@ import (
 	"fmt"
 	"io/ioutil"
 	"net/http"
+	"net/url"
+	"strings"
 	"time"
 
 	"golang.org/x/oauth2"
 )
 
-var (
-	httpClient = &http.Client{Timeout: time.Second * 10}
-)
-
 // NewConfig returns a new TikTok oauth2 config based on provided arguments.
 func NewConfig(clientID, clientSecret, redirectURL string, scopes ...string) (*oauth2.Config, error) {
+	return defaultClient.NewConfig(clientID, clientSecret, redirectURL, scopes...)
+}
+
+// ConfigExchange converts an oauth2 config and authorization code into an oauth2 token.
+func ConfigExchange(ctx context.Context, config *oauth2.Config, code string) (*oauth2.Token, error) {
+	return defaultClient.ConfigExchange(ctx, config, code)
+}
+
+// RefreshToken refreshes the access token of the user.
+func RefreshToken(ctx context.Context, config *oauth2.Config, refreshToken string) (*oauth2.Token, error) {
+	return defaultClient.RefreshToken(ctx, config, refreshToken)
+}
+
+// RevokeAccess revokes a user's access token.
+func RevokeAccess(ctx context.Context, config *oauth2.Config, token *oauth2.Token) error {
+	return defaultClient.RevokeAccess(ctx, config, token)
+}
+
+// RetrieveUserInfo returns some basic information of a given TikTok user based on the open id.
+func RetrieveUserInfo(ctx context.Context, token *oauth2.Token, fields ...string) (*UserInfo, error) {
+	return defaultClient.RetrieveUserInfo(ctx, token, fields...)
+}
+
+// NewConfig returns a new TikTok oauth2 config pointing at the client's API host.
+func (c *Client) NewConfig(clientID, clientSecret, redirectURL string, scopes ...string) (*oauth2.Config, error) {
 	if clientID == "" {
 		return nil, fmt.Errorf("tiktok-oauth2: NewConfig: client id cannot be empty")
 	}
@@ -36,11 +59,16 @@ func NewConfig(clientID, clientSecret, redirectURL string, scopes ...string) (*o
 		Scopes:       scopes,
 		Endpoint: oauth2.Endpoint{
 			AuthURL:   endpointAuth,
-			TokenURL:  endpointToken,
+			TokenURL:  c.endpoint(pathToken),
 			AuthStyle: oauth2.AuthStyleInParams,
 		},
 	}
 
+	if c.version == APIVersionV1 {
+		cfg.Endpoint.AuthURL = c.endpoint(pathAuthV1)
+		cfg.Endpoint.TokenURL = c.endpoint(pathTokenV1)
+	}
+
 	if len(cfg.Scopes) == 0 {
 		cfg.Scopes = []string{"user.info.basic"}
 	}
@@ -48,8 +76,17 @@ func NewConfig(clientID, clientSecret, redirectURL string, scopes ...string) (*o
 	return cfg, nil
 }
 
+// AuthCodeURL returns the TikTok consent page URL for the provided config and state.
+// TikTok expects 'client_key' instead of 'client_id' and a comma separated scope list.
+func AuthCodeURL(config *oauth2.Config, state string) string {
+	return config.AuthCodeURL(state,
+		oauth2.SetAuthURLParam("client_key", config.ClientID),
+		oauth2.SetAuthURLParam("scope", strings.Join(config.Scopes, ",")),
+	)
+}
+
 // ConfigExchange converts an oauth2 config and authorization code into an oauth2 token.
-func ConfigExchange(ctx context.Context, config *oauth2.Config, code string) (*oauth2.Token, error) {
+func (c *Client) ConfigExchange(ctx context.Context, config *oauth2.Config, code string) (*oauth2.Token, error) {
 	if config == nil {
 		return nil, fmt.Errorf("tiktok-oauth2: ConfigExchange: config cannot be nil")
 	}
@@ -58,214 +95,207 @@ func ConfigExchange(ctx context.Context, config *oauth2.Config, code string) (*o
 		return nil, fmt.Errorf("tiktok-oauth2: ConfigExchange: code cannot be empty")
 	}
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpointToken, nil)
-	if err != nil {
-		return nil, fmt.Errorf("tiktok-oauth2: ConfigExchange: %w", err)
+	if c.version == APIVersionV1 {
+		return c.configExchangeV1(ctx, config, code)
 	}
 
-	q := req.URL.Query()
-	q.Add("client_key", config.ClientID)
-	q.Add("client_secret", config.ClientSecret)
-	q.Add("code", code)
-	q.Add("grant_type", "authorization_code")
-	req.URL.RawQuery = q.Encode()
+	form := url.Values{}
+	form.Add("client_key", config.ClientID)
+	form.Add("client_secret", config.ClientSecret)
+	form.Add("code", code)
+	form.Add("grant_type", "authorization_code")
+	form.Add("redirect_uri", config.RedirectURL)
 
-	response, err := httpClient.Do(req)
+	token, err := c.requestToken(ctx, form)
 	if err != nil {
 		return nil, fmt.Errorf("tiktok-oauth2: ConfigExchange: %w", err)
 	}
 
-	defer response.Body.Close()
-
-	bodyBytes, err := ioutil.ReadAll(response.Body)
-	if err != nil {
-		return nil, fmt.Errorf("tiktok-oauth2: ConfigExchange: %w", err)
-	}
+	return token, nil
+}
 
-	var body tokenResponse
-	if err = json.Unmarshal(bodyBytes, &body); err != nil {
-		return nil, fmt.Errorf("tiktok-oauth2: ConfigExchange: %w", err)
+// RefreshToken refreshes the access token of the user.
+// The v2 API requires the client secret, the v1 API only uses the client id of the config.
+func (c *Client) RefreshToken(ctx context.Context, config *oauth2.Config, refreshToken string) (*oauth2.Token, error) {
+	if config == nil || config.ClientID == "" {
+		return nil, fmt.Errorf("tiktok-oauth2: RefreshToken: client id cannot be empty")
 	}
 
-	if body == (tokenResponse{}) {
-		return nil, fmt.Errorf("tiktok-oauth2: ConfigExchange: %w", handleErrorResponse(bodyBytes))
+	if refreshToken == "" {
+		return nil, fmt.Errorf("tiktok-oauth2: RefreshToken: refresh token cannot be empty")
 	}
 
-	token := &oauth2.Token{
-		AccessToken:  body.Data.AccessToken,
-		TokenType:    "Bearer",
-		RefreshToken: body.Data.RefreshToken,
-		Expiry:       time.Now().Add(time.Second * time.Duration(body.Data.ExpiresIn)),
+	if c.version == APIVersionV1 {
+		return c.refreshTokenV1(ctx, config.ClientID, refreshToken)
 	}
 
-	if token.AccessToken == "" {
-		return nil, fmt.Errorf("tiktok-oauth2: ConfigExchange: server response missing access_token")
-	}
+	form := url.Values{}
+	form.Add("client_key", config.ClientID)
+	form.Add("client_secret", config.ClientSecret)
+	form.Add("grant_type", "refresh_token")
+	form.Add("refresh_token", refreshToken)
 
-	tokenExtra := map[string]interface{}{
-		"open_id":            body.Data.OpenID,
-		"scope":              body.Data.Scope,
-		"refresh_expires_in": body.Data.RefreshExpiresIn,
+	token, err := c.requestToken(ctx, form)
+	if err != nil {
+		return nil, fmt.Errorf("tiktok-oauth2: RefreshToken: %w", err)
 	}
 
-	return token.WithExtra(tokenExtra), nil
+	return token, nil
 }
 
-// RefreshToken refreshes the access token of the user.
-func RefreshToken(ctx context.Context, clientID, refreshToken string) (*oauth2.Token, error) {
-	if clientID == "" {
-		return nil, fmt.Errorf("tiktok-oauth2: RefreshToken: client id cannot be empty")
+// RevokeAccess revokes a user's access token.
+// The v2 API requires the client credentials of the config, the v1 API ignores it.
+func (c *Client) RevokeAccess(ctx context.Context, config *oauth2.Config, token *oauth2.Token) error {
+	if c.version == APIVersionV1 {
+		return c.revokeAccessV1(ctx, token)
 	}
 
-	if refreshToken == "" {
-		return nil, fmt.Errorf("tiktok-oauth2: RefreshToken: refresh token cannot be empty")
+	if config == nil {
+		return fmt.Errorf("tiktok-oauth2: RevokeAccess: config cannot be nil")
 	}
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpointRefresh, nil)
-	if err != nil {
-		return nil, fmt.Errorf("tiktok-oauth2: %w", err)
+	if token == nil || token.AccessToken == "" {
+		return fmt.Errorf("tiktok-oauth2: RevokeAccess: token cannot be empty")
 	}
 
-	q := req.URL.Query()
-	q.Add("client_key", clientID)
-	q.Add("refresh_token", refreshToken)
-	q.Add("grant_type", "refresh_token")
-	req.URL.RawQuery = q.Encode()
+	form := url.Values{}
+	form.Add("client_key", config.ClientID)
+	form.Add("client_secret", config.ClientSecret)
+	form.Add("token", token.AccessToken)
 
-	response, err := httpClient.Do(req)
+	bodyBytes, err := c.postForm(ctx, pathRevoke, form)
 	if err != nil {
-		return nil, fmt.Errorf("tiktok-oauth2: RefreshToken: %w", err)
+		return fmt.Errorf("tiktok-oauth2: RevokeAccess: %w", err)
 	}
 
-	defer response.Body.Close()
-
-	bodyBytes, err := ioutil.ReadAll(response.Body)
-	if err != nil {
-		return nil, fmt.Errorf("tiktok-oauth2: RefreshToken: %w", err)
+	//- a successful revoke answers with an empty body
+	if len(strings.TrimSpace(string(bodyBytes))) == 0 {
+		return nil
 	}
 
-	var body tokenResponse
+	var body tokenResponseV2
 	if err = json.Unmarshal(bodyBytes, &body); err != nil {
-		return nil, fmt.Errorf("tiktok-oauth2: RefreshToken: %w", err)
+		return fmt.Errorf("tiktok-oauth2: RevokeAccess: %w", err)
 	}
 
-	if body == (tokenResponse{}) {
-		return nil, fmt.Errorf("tiktok-oauth2: RefreshToken: %w", handleErrorResponse(bodyBytes))
+	if body.Error != "" {
+		return fmt.Errorf("tiktok-oauth2: RevokeAccess: %w", &OAuthError{Code: body.Error, Description: body.ErrorDescription, LogID: body.LogID})
 	}
 
-	token := &oauth2.Token{
-		AccessToken:  body.Data.AccessToken,
-		TokenType:    "Bearer",
-		RefreshToken: body.Data.RefreshToken,
-		Expiry:       time.Now().Add(time.Second * time.Duration(body.Data.ExpiresIn)),
-	}
+	return nil
+}
 
-	if token.AccessToken == "" {
-		return nil, fmt.Errorf("tiktok-oauth2: RefreshToken: server response missing access_token")
+// RetrieveUserInfo returns some basic information of a given TikTok user based on the open id.
+// The v2 API only returns the requested fields, DefaultUserInfoFields are used when none are given.
+func (c *Client) RetrieveUserInfo(ctx context.Context, token *oauth2.Token, fields ...string) (*UserInfo, error) {
+	if c.version == APIVersionV1 {
+		return c.retrieveUserInfoV1(ctx, token)
 	}
 
-	tokenExtra := map[string]interface{}{
-		"open_id":            body.Data.OpenID,
-		"scope":              body.Data.Scope,
-		"refresh_expires_in": body.Data.RefreshExpiresIn,
+	if token == nil || token.AccessToken == "" {
+		return nil, fmt.Errorf("tiktok-oauth2: RetrieveUserInfo: token cannot be empty")
 	}
 
-	return token.WithExtra(tokenExtra), nil
-}
-
-// RevokeAccess revokes a user's access token.
-func RevokeAccess(ctx context.Context, token *oauth2.Token) error {
-	openID, err := OpenIDFromToken(token)
-	if err != nil {
-		return fmt.Errorf("tiktok-oauth2: RevokeAccess: failed to get open_id from token")
+	if len(fields) == 0 {
+		fields = DefaultUserInfoFields
 	}
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpointRevoke, nil)
+	endpoint := c.endpoint(pathUserInfo) + "?" + url.Values{"fields": {strings.Join(fields, ",")}}.Encode()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
 	if err != nil {
-		return fmt.Errorf("tiktok-oauth2: RevokeAccess: %w", err)
+		return nil, fmt.Errorf("tiktok-oauth2: RetrieveUserInfo: %w", err)
 	}
+	req.Header.Set("Authorization", "Bearer "+token.AccessToken)
 
-	q := req.URL.Query()
-	q.Add("access_token", token.AccessToken)
-	q.Add("open_id", openID)
-	req.URL.RawQuery = q.Encode()
-
-	response, err := httpClient.Do(req)
+	response, err := c.httpClient.Do(req)
 	if err != nil {
-		return fmt.Errorf("tiktok-oauth2: RevokeAccess: %w", err)
+		return nil, fmt.Errorf("tiktok-oauth2: RetrieveUserInfo: %w", err)
 	}
 
 	defer response.Body.Close()
 
 	bodyBytes, err := ioutil.ReadAll(response.Body)
 	if err != nil {
-		return fmt.Errorf("tiktok-oauth2: RevokeAccess: %w", err)
+		return nil, fmt.Errorf("tiktok-oauth2: RetrieveUserInfo: %w", err)
 	}
 
-	var body revokeResponse
-	if err = json.Unmarshal(bodyBytes, &body); err != nil {
-		return fmt.Errorf("tiktok-oauth2: RevokeAccess: %w", err)
+	if err = handleAPIError(response.StatusCode, bodyBytes); err != nil {
+		return nil, fmt.Errorf("tiktok-oauth2: RetrieveUserInfo: %w", err)
 	}
 
-	if body.Message != "success" {
-		return fmt.Errorf("tiktok-oauth2: RevokeAccess: %w", handleErrorResponse(bodyBytes))
+	var body userInfoResponseV2
+	if err = json.Unmarshal(bodyBytes, &body); err != nil {
+		return nil, fmt.Errorf("tiktok-oauth2: RetrieveUserInfo: %w", err)
 	}
 
-	return nil
+	user := body.Data.User
+	return &UserInfo{
+		OpenID:          user.OpenID,
+		UnionID:         user.UnionID,
+		Avatar:          user.AvatarURL,
+		AvatarLarger:    user.AvatarLargeURL,
+		DisplayName:     user.DisplayName,
+		BioDescription:  user.BioDescription,
+		ProfileDeepLink: user.ProfileDeepLink,
+		IsVerified:      user.IsVerified,
+		FollowerCount:   user.FollowerCount,
+		FollowingCount:  user.FollowingCount,
+		LikesCount:      user.LikesCount,
+		VideoCount:      user.VideoCount,
+	}, nil
 }
 
-// RetrieveUserInfo returns some basic information of a given TikTok user based on the open id.
-func RetrieveUserInfo(ctx context.Context, token *oauth2.Token) (*UserInfo, error) {
-	openID, err := OpenIDFromToken(token)
+// requestToken posts the form to the v2 token endpoint and converts the flat response into an oauth2 token.
+func (c *Client) requestToken(ctx context.Context, form url.Values) (*oauth2.Token, error) {
+	bodyBytes, err := c.postForm(ctx, pathToken, form)
 	if err != nil {
-		return nil, fmt.Errorf("tiktok-oauth2: RetrieveUserInfo: failed to get open_id from token")
+		return nil, err
 	}
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpointUserInfo, nil)
-	if err != nil {
-		return nil, fmt.Errorf("tiktok-oauth2: RetrieveUserInfo: %w", err)
+	var body tokenResponseV2
+	if err = json.Unmarshal(bodyBytes, &body); err != nil {
+		return nil, err
 	}
 
-	q := req.URL.Query()
-	q.Add("access_token", token.AccessToken)
-	q.Add("open_id", openID)
-	req.URL.RawQuery = q.Encode()
-
-	response, err := httpClient.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("tiktok-oauth2: RetrieveUserInfo: %w", err)
+	if body.Error != "" {
+		return nil, &OAuthError{Code: body.Error, Description: body.ErrorDescription, LogID: body.LogID}
 	}
 
-	defer response.Body.Close()
-
-	bodyBytes, err := ioutil.ReadAll(response.Body)
-	if err != nil {
-		return nil, fmt.Errorf("tiktok-oauth2: RetrieveUserInfo: %w", err)
+	if body.AccessToken == "" {
+		return nil, fmt.Errorf("server response missing access_token")
 	}
 
-	var body userInfoResponse
-	if err = json.Unmarshal(bodyBytes, &body); err != nil {
-		return nil, fmt.Errorf("tiktok-oauth2: RetrieveUserInfo: %w", err)
+	token := &oauth2.Token{
+		AccessToken:  body.AccessToken,
+		TokenType:    "Bearer",
+		RefreshToken: body.RefreshToken,
+		Expiry:       time.Now().Add(time.Second * time.Duration(body.ExpiresIn)),
 	}
 
-	if body == (userInfoResponse{}) {
-		return nil, fmt.Errorf("tiktok-oauth2: RetrieveUserInfo: %w", handleErrorResponse(bodyBytes))
+	tokenExtra := map[string]interface{}{
+		"open_id":            body.OpenID,
+		"scope":              body.Scope,
+		"refresh_expires_in": body.RefreshExpiresIn,
 	}
 
-	return &UserInfo{
-		OpenID:       body.Data.OpenID,
-		UnionID:      body.Data.UnionID,
-		Avatar:       body.Data.Avatar,
-		AvatarLarger: body.Data.AvatarLarger,
-		DisplayName:  body.Data.DisplayName,
-	}, nil
+	return token.WithExtra(tokenExtra), nil
 }
 
-func handleErrorResponse(data []byte) error {
-	var errBody errorResponse
-	if err := json.Unmarshal(data, &errBody); err != nil {
-		return err
+// postForm sends a form encoded POST request and returns the raw response body.
+func (c *Client) postForm(ctx context.Context, path string, form url.Values) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.endpoint(path), strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
 	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Cache-Control", "no-cache")
+
+	response, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	defer response.Body.Close()
 
-	return fmt.Errorf("%s [%d]", errBody.Data.Description, errBody.Data.ErrorCode)
+	return ioutil.ReadAll(response.Body)
 }
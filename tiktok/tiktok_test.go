@@ -0,0 +1,55 @@
+package tiktok
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuthCodeURL(t *testing.T) {
+	config, err := NewConfig("client-key", "client-secret", "http://localhost/callback", "user.info.basic", "video.list")
+	assert.Nil(t, err)
+
+	authURL, err := url.Parse(AuthCodeURL(config, "state-key"))
+	assert.Nil(t, err)
+
+	query := authURL.Query()
+	assert.Equal(t, "client-key", query.Get("client_key"))
+	assert.Equal(t, "user.info.basic,video.list", query.Get("scope"))
+	assert.Equal(t, "state-key", query.Get("state"))
+	assert.Equal(t, "code", query.Get("response_type"))
+}
+
+func TestNewConfigDefaultScope(t *testing.T) {
+	config, err := NewConfig("client-key", "client-secret", "http://localhost/callback")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"user.info.basic"}, config.Scopes)
+
+	_, err = NewConfig("", "client-secret", "http://localhost/callback")
+	assert.NotNil(t, err)
+}
+
+func TestChunkPlan(t *testing.T) {
+	tests := []struct {
+		name          string
+		videoSize     int64
+		chunkSize     int64
+		wantChunkSize int64
+		wantCount     int64
+	}{
+		{name: "small video is a single chunk", videoSize: 3 << 20, chunkSize: 10 << 20, wantChunkSize: 3 << 20, wantCount: 1},
+		{name: "remainder goes to last chunk", videoSize: 25<<20 + 7, chunkSize: 10 << 20, wantChunkSize: 10 << 20, wantCount: 2},
+		{name: "chunk size raised to minimum", videoSize: 12 << 20, chunkSize: 1 << 20, wantChunkSize: 5 << 20, wantCount: 2},
+		{name: "chunk size capped to maximum", videoSize: 200 << 20, chunkSize: 100 << 20, wantChunkSize: 64 << 20, wantCount: 3},
+	}
+
+	for _, testCase := range tests {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			chunkSize, count := ChunkPlan(testCase.videoSize, testCase.chunkSize)
+			assert.Equal(t, testCase.wantChunkSize, chunkSize)
+			assert.Equal(t, testCase.wantCount, count)
+		})
+	}
+}
@@ -1,60 +1,218 @@
 package tiktok
 
+import (
+	"encoding/json"
+	"fmt"
+)
+
+const (
+	// DefaultBaseURL is the host of the TikTok v2 API.
+	DefaultBaseURL = "https://open.tiktokapis.com"
+	// V1BaseURL is the host of the deprecated TikTok v1 API.
+	V1BaseURL = "https://open-api.tiktok.com"
+
+	// the v2 consent page is served by the main TikTok website rather than the API host
+	endpointAuth = "https://www.tiktok.com/v2/auth/authorize/"
+
+	pathToken    = "/v2/oauth/token/"
+	pathRevoke   = "/v2/oauth/revoke/"
+	pathUserInfo = "/v2/user/info/"
+
+	pathAuthV1     = "/platform/oauth/connect/"
+	pathTokenV1    = "/oauth/access_token/"
+	pathRefreshV1  = "/oauth/refresh_token/"
+	pathRevokeV1   = "/oauth/revoke/"
+	pathUserInfoV1 = "/oauth/userinfo/"
+
+	pathPublishVideoInit   = "/v2/post/publish/video/init/"
+	pathPublishStatusFetch = "/v2/post/publish/status/fetch/"
+	pathVideoList          = "/v2/video/list/"
+	pathVideoQuery         = "/v2/video/query/"
+)
+
+// APIVersion selects which generation of the TikTok API a Client talks to.
+type APIVersion string
+
 const (
-	endpointAuth     = "https://open-api.tiktok.com/platform/oauth/connect/"
-	endpointToken    = "https://open-api.tiktok.com/oauth/access_token/"
-	endpointRefresh  = "https://open-api.tiktok.com/oauth/refresh_token/"
-	endpointRevoke   = "https://open-api.tiktok.com/oauth/revoke/"
-	endpointUserInfo = "https://open-api.tiktok.com/oauth/userinfo/"
+	APIVersionV1 APIVersion = "v1"
+	APIVersionV2 APIVersion = "v2"
 )
 
+// DefaultUserInfoFields are the v2 user info fields covered by the user.info.basic scope.
+var DefaultUserInfoFields = []string{"open_id", "union_id", "avatar_url", "avatar_large_url", "display_name"}
+
 // UserInfo holds some basic information of a given TikTok user.
+// Profile and stats fields are only filled when requested and granted through the v2 API.
 type UserInfo struct {
-	OpenID       string
-	UnionID      string
-	Avatar       string
-	AvatarLarger string
-	DisplayName  string
+	OpenID       string `json:"open_id"`
+	UnionID      string `json:"union_id"`
+	Avatar       string `json:"avatar"`
+	AvatarLarger string `json:"avatar_larger"`
+	DisplayName  string `json:"display_name"`
+
+	BioDescription  string `json:"bio_description,omitempty"`
+	ProfileDeepLink string `json:"profile_deep_link,omitempty"`
+	IsVerified      bool   `json:"is_verified,omitempty"`
+	FollowerCount   int64  `json:"follower_count,omitempty"`
+	FollowingCount  int64  `json:"following_count,omitempty"`
+	LikesCount      int64  `json:"likes_count,omitempty"`
+	VideoCount      int64  `json:"video_count,omitempty"`
 }
 
-type userInfoResponse struct {
+type userInfoResponseV2 struct {
 	Data struct {
-		OpenID       string `json:"open_id"`
-		UnionID      string `json:"union_id"`
-		Avatar       string `json:"avatar"`
-		AvatarLarger string `json:"avatar_larger"`
-		DisplayName  string `json:"display_name"`
+		User struct {
+			OpenID          string `json:"open_id"`
+			UnionID         string `json:"union_id"`
+			AvatarURL       string `json:"avatar_url"`
+			AvatarLargeURL  string `json:"avatar_large_url"`
+			DisplayName     string `json:"display_name"`
+			BioDescription  string `json:"bio_description"`
+			ProfileDeepLink string `json:"profile_deep_link"`
+			IsVerified      bool   `json:"is_verified"`
+			FollowerCount   int64  `json:"follower_count"`
+			FollowingCount  int64  `json:"following_count"`
+			LikesCount      int64  `json:"likes_count"`
+			VideoCount      int64  `json:"video_count"`
+		} `json:"user"`
+	} `json:"data"`
+}
+
+type tokenResponseV2 struct {
+	OpenID           string `json:"open_id"`
+	Scope            string `json:"scope"`
+	AccessToken      string `json:"access_token"`
+	ExpiresIn        int64  `json:"expires_in"`
+	RefreshToken     string `json:"refresh_token"`
+	RefreshExpiresIn int64  `json:"refresh_expires_in"`
+	TokenType        string `json:"token_type"`
+
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+	LogID            string `json:"log_id"`
+}
+
+// OAuthError is returned when TikTok answers an OAuth request with an error,
+// e.g. a refresh token that has expired or been revoked by the user.
+type OAuthError struct {
+	Code        string
+	Description string
+	LogID       string
+}
+
+func (e *OAuthError) Error() string {
+	if e.LogID == "" {
+		return fmt.Sprintf("%s [%s]", e.Description, e.Code)
 	}
+	return fmt.Sprintf("%s [%s] (log id %s)", e.Description, e.Code, e.LogID)
+}
+
+// PostInfo holds the settings of a video post published through the Content Posting API.
+type PostInfo struct {
+	Title                 string `json:"title,omitempty"`
+	PrivacyLevel          string `json:"privacy_level"`
+	DisableDuet           bool   `json:"disable_duet"`
+	DisableComment        bool   `json:"disable_comment"`
+	DisableStitch         bool   `json:"disable_stitch"`
+	VideoCoverTimestampMs int64  `json:"video_cover_timestamp_ms,omitempty"`
+}
+
+// UploadSession holds the publish id and upload url returned when a video upload is initialized.
+type UploadSession struct {
+	PublishID       string
+	UploadURL       string
+	VideoSize       int64
+	ChunkSize       int64
+	TotalChunkCount int64
+}
+
+// PublishStatus holds the current state of a video post.
+type PublishStatus struct {
+	Status        string   `json:"status"`
+	FailReason    string   `json:"fail_reason,omitempty"`
+	PostIDs       []string `json:"post_ids,omitempty"`
+	UploadedBytes int64    `json:"uploaded_bytes"`
+}
+
+type sourceInfo struct {
+	Source          string `json:"source"`
+	VideoSize       int64  `json:"video_size"`
+	ChunkSize       int64  `json:"chunk_size"`
+	TotalChunkCount int64  `json:"total_chunk_count"`
+}
+
+type publishInitRequest struct {
+	PostInfo   PostInfo   `json:"post_info"`
+	SourceInfo sourceInfo `json:"source_info"`
 }
 
-type tokenResponse struct {
+type publishInitResponse struct {
 	Data struct {
-		OpenID           string `json:"open_id"`
-		Scope            string `json:"scope"`
-		AccessToken      string `json:"access_token"`
-		ExpiresIn        int64  `json:"expires_in"`
-		RefreshToken     string `json:"refresh_token"`
-		RefreshExpiresIn int64  `json:"refresh_expires_in"`
+		PublishID string `json:"publish_id"`
+		UploadURL string `json:"upload_url"`
 	} `json:"data"`
+	Error apiError `json:"error"`
 }
 
-type revokeResponse struct {
+type publishStatusResponse struct {
 	Data struct {
-		Captcha     string `json:"captcha"`
-		DescURL     string `json:"desc_url"`
-		Description string `json:"description"`
-		ErrorCode   int    `json:"error_code"`
-		LogID       string `json:"log_id"`
-	}
+		Status                   string        `json:"status"`
+		FailReason               string        `json:"fail_reason"`
+		PubliclyAvailablePostIDs []json.Number `json:"publicaly_available_post_id"`
+		UploadedBytes            int64         `json:"uploaded_bytes"`
+	} `json:"data"`
+	Error apiError `json:"error"`
+}
+
+type apiError struct {
+	Code    string `json:"code"`
 	Message string `json:"message"`
+	LogID   string `json:"log_id"`
 }
 
-type errorResponse struct {
+// APIError is returned when TikTok answers an API or upload request with an error,
+// StatusCode is the HTTP status of that answer.
+type APIError struct {
+	StatusCode int
+	Code       string
+	Message    string
+	LogID      string
+}
+
+func (e *APIError) Error() string {
+	if e.Code == "" {
+		return fmt.Sprintf("status %d: %s", e.StatusCode, e.Message)
+	}
+	return fmt.Sprintf("%s [%s] (log id %s)", e.Message, e.Code, e.LogID)
+}
+
+// Video holds the public fields and engagement counters of a TikTok video.
+type Video struct {
+	ID            string `json:"id"`
+	Title         string `json:"title"`
+	CoverImageURL string `json:"cover_image_url"`
+	ShareURL      string `json:"share_url"`
+	CreateTime    int64  `json:"create_time"`
+	Duration      int64  `json:"duration"`
+	ViewCount     int64  `json:"view_count"`
+	LikeCount     int64  `json:"like_count"`
+	CommentCount  int64  `json:"comment_count"`
+	ShareCount    int64  `json:"share_count"`
+}
+
+// VideoList holds a page of the user's videos and the cursor of the next page.
+type VideoList struct {
+	Videos  []Video `json:"videos"`
+	Cursor  int64   `json:"cursor"`
+	HasMore bool    `json:"has_more"`
+}
+
+type videoListResponse struct {
+	Data VideoList `json:"data"`
+}
+
+type videoQueryResponse struct {
 	Data struct {
-		Captcha     string `json:"captcha"`
-		DescURL     string `json:"desc_url"`
-		Description string `json:"description"`
-		ErrorCode   int    `json:"error_code"`
+		Videos []Video `json:"videos"`
 	} `json:"data"`
-	Message string `json:"message"`
 }
@@ -1 +1,252 @@
 package usecase
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"tiktok_api/app/logger"
+	"tiktok_api/domain"
+	"tiktok_api/tiktok"
+	"tiktok_api/tiktok/repository/redis"
+	"time"
+
+	"github.com/spf13/viper"
+	"golang.org/x/oauth2"
+)
+
+var log = logger.NewLogrusLogger()
+var ctx = context.Background()
+
+var ErrNotConnected = errors.New("tiktok account has not been connected")
+var ErrReconnectRequired = errors.New("tiktok account has to be reconnected, its refresh token is no longer valid")
+var ErrLegacyAPIVersion = errors.New("tiktok account was connected through the v1 api, reconnect it to use video endpoints")
+
+func handleError(err error, message string, errorType string) {
+	fields := logger.Fields{
+		"service": "Tiktok",
+		"message": message,
+	}
+	switch errorType {
+	case "fatal":
+		log.Fields(fields).Fatalf(err, message)
+	case "error":
+		log.Fields(fields).Errorf(err, message)
+	case "warn":
+		log.Fields(fields).Warnf(message)
+	case "info":
+		log.Fields(fields).Infof(message)
+	case "debug":
+		log.Fields(fields).Debugf(message)
+	}
+}
+
+// - version used to connect new accounts, v2 unless the config still asks for v1
+func configuredAPIVersion() tiktok.APIVersion {
+	if viper.GetString("TIKTOK.API_VERSION") == string(tiktok.APIVersionV1) {
+		return tiktok.APIVersionV1
+	}
+	return tiktok.APIVersionV2
+}
+
+// - records created before the v2 migration carry no version, their tokens only work on the v1 api
+func apiVersionOf(tiktokOAuth *domain.TiktokOAuth) tiktok.APIVersion {
+	if tiktokOAuth.APIVersion == "" {
+		return tiktok.APIVersionV1
+	}
+	return tiktok.APIVersion(tiktokOAuth.APIVersion)
+}
+
+// - clients and config are built on every call because viper is only loaded after package init
+func apiClient(version tiktok.APIVersion) *tiktok.Client {
+	if version == tiktok.APIVersionV1 {
+		baseURL := viper.GetString("TIKTOK.V1_API_BASE_URL")
+		if baseURL == "" {
+			baseURL = tiktok.V1BaseURL
+		}
+		return tiktok.NewClient(baseURL, nil, tiktok.WithAPIVersion(tiktok.APIVersionV1))
+	}
+	return tiktok.NewClient(viper.GetString("TIKTOK.API_BASE_URL"), nil)
+}
+
+func contentClient() *tiktok.Client {
+	//- video chunks are much larger than regular api calls
+	return tiktok.NewClient(viper.GetString("TIKTOK.API_BASE_URL"), &http.Client{Timeout: 2 * time.Minute})
+}
+
+func buildConfig(version tiktok.APIVersion) (*oauth2.Config, error) {
+	var scopes []string
+	for _, scope := range strings.Split(viper.GetString("TIKTOK.SCOPES"), ",") {
+		if scope = strings.TrimSpace(scope); scope != "" {
+			scopes = append(scopes, scope)
+		}
+	}
+	return apiClient(version).NewConfig(
+		viper.GetString("TIKTOK.CLIENT_KEY"),
+		viper.GetString("TIKTOK.CLIENT_SECRET"),
+		viper.GetString("TIKTOK.REDIRECT_URL"),
+		scopes...,
+	)
+}
+
+func GetAuthURL() (string, string, error) {
+	version := configuredAPIVersion()
+	config, err := buildConfig(version)
+	if err != nil {
+		handleError(err, "Unable to build Tiktok OAuth config", "error")
+		return "", "", err
+	}
+
+	clientKey, err := redis.CreateNewTiktokClient(string(version))
+	if err != nil {
+		handleError(err, "Error when call CreateNewTiktokClient", "error")
+		return "", "", err
+	}
+	state := clientKey
+	return tiktok.AuthCodeURL(config, state), clientKey, nil
+}
+
+func TiktokOAuthCodeExchange(clientKey string, code string) string {
+	successURL := viper.GetString("TIKTOK.REDIRECT_URL_SUCCESS")
+	failURL := viper.GetString("TIKTOK.REDIRECT_URL_ERROR")
+
+	//- check if clientKey is exist
+	//- if not exist, CSRF => reject
+	if !redis.IsExist(clientKey) {
+		handleError(errors.New("redis.IsExist"), "Error when call redis.IsExist", "error")
+		return failURL
+	}
+
+	tiktokOAuth, err := redis.GetClientByClientKey(clientKey)
+	if err != nil {
+		handleError(err, "Error when call GetClientByClientKey", "error")
+		return failURL
+	}
+
+	//- exchange on the api version the auth url was generated for
+	version := apiVersionOf(tiktokOAuth)
+	config, err := buildConfig(version)
+	if err != nil {
+		handleError(err, "Unable to build Tiktok OAuth config", "error")
+		return failURL
+	}
+
+	tokens, err := apiClient(version).ConfigExchange(ctx, config, code)
+	if err != nil {
+		handleError(err, "Unable to retrieve token from web", "error")
+		return failURL
+	}
+	if err = applyToken(tiktokOAuth, tokens); err != nil {
+		handleError(err, "Tiktok token response is missing extra fields", "error")
+		return failURL
+	}
+
+	isUpdate := redis.UpdateTiktokByClientKey(clientKey, tiktokOAuth)
+	if !isUpdate {
+		handleError(errors.New("redis.UpdateTiktokByClientKey"), "Error when call UpdateTiktokByClientKey", "error")
+		return failURL
+	}
+
+	return successURL
+}
+
+func RevokeAccess(clientKey string) error {
+	tiktokOAuth, err := getConnectedClient(clientKey)
+	if errors.Is(err, ErrReconnectRequired) {
+		//- tiktok already refuses these tokens, only the stored data is left to clean up
+		return deleteClientData(clientKey)
+	}
+	if err != nil {
+		return err
+	}
+
+	version := apiVersionOf(tiktokOAuth)
+	config, err := buildConfig(version)
+	if err != nil {
+		handleError(err, "Unable to build Tiktok OAuth config", "error")
+		return err
+	}
+
+	err = apiClient(version).RevokeAccess(ctx, config, buildToken(tiktokOAuth))
+	if err != nil {
+		handleError(err, "Error when call RevokeAccess", "error")
+		return err
+	}
+
+	return deleteClientData(clientKey)
+}
+
+func deleteClientData(clientKey string) error {
+	//- cached profile belongs to the revoked account
+	if err := redis.DeleteUserInfo(clientKey); err != nil {
+		return err
+	}
+	return redis.DeleteTiktokByClientKey(clientKey)
+}
+
+// - applyToken copies an exchanged or refreshed token and its extras onto the stored record
+func applyToken(tiktokOAuth *domain.TiktokOAuth, token *oauth2.Token) error {
+	openID, err := tiktok.OpenIDFromToken(token)
+	if err != nil {
+		return err
+	}
+	scope, err := tiktok.ScopeFromToken(token)
+	if err != nil {
+		return err
+	}
+	refreshExpiresIn, err := tiktok.RefreshExpiresInFromToken(token)
+	if err != nil {
+		return err
+	}
+
+	tiktokOAuth.AccessToken = token.AccessToken
+	tiktokOAuth.RefreshToken = token.RefreshToken
+	tiktokOAuth.Expiry = token.Expiry
+	tiktokOAuth.OpenID = openID
+	tiktokOAuth.Scope = scope
+	tiktokOAuth.RefreshExpiresIn = refreshExpiresIn
+	tiktokOAuth.RefreshExpiry = time.Now().Add(time.Duration(refreshExpiresIn) * time.Second)
+	tiktokOAuth.ReconnectRequired = false
+	return nil
+}
+
+// - buildToken turns a stored record back into a token the tiktok package understands
+func buildToken(tiktokOAuth *domain.TiktokOAuth) *oauth2.Token {
+	token := &oauth2.Token{
+		AccessToken:  tiktokOAuth.AccessToken,
+		RefreshToken: tiktokOAuth.RefreshToken,
+		Expiry:       tiktokOAuth.Expiry,
+		TokenType:    "Bearer",
+	}
+	return token.WithExtra(map[string]interface{}{
+		"open_id":            tiktokOAuth.OpenID,
+		"scope":              tiktokOAuth.Scope,
+		"refresh_expires_in": tiktokOAuth.RefreshExpiresIn,
+	})
+}
+
+func getConnectedClient(clientKey string) (*domain.TiktokOAuth, error) {
+	tiktokOAuth, err := redis.GetClientByClientKey(clientKey)
+	if err != nil {
+		return nil, err
+	}
+	if !tiktokOAuth.Connected() {
+		return nil, ErrNotConnected
+	}
+	if tiktokOAuth.ReconnectRequired {
+		return nil, ErrReconnectRequired
+	}
+	return tiktokOAuth, nil
+}
+
+// - video endpoints only exist on the v2 api, v1 tokens are rejected by them
+func getContentClient(clientKey string) (*domain.TiktokOAuth, error) {
+	tiktokOAuth, err := getConnectedClient(clientKey)
+	if err != nil {
+		return nil, err
+	}
+	if apiVersionOf(tiktokOAuth) != tiktok.APIVersionV2 {
+		return nil, ErrLegacyAPIVersion
+	}
+	return tiktokOAuth, nil
+}
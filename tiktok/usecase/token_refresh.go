@@ -0,0 +1,82 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"tiktok_api/domain"
+	"tiktok_api/tiktok"
+	"tiktok_api/tiktok/repository/redis"
+	"time"
+)
+
+var ErrRefreshTokenExpired = errors.New("tiktok refresh token has expired")
+
+// - RefreshExpiringTokens refreshes every tiktok access token expiring within the given window.
+// - Accounts whose refresh token is expired or rejected by tiktok are marked as reconnect required.
+func RefreshExpiringTokens(within time.Duration) error {
+	clientKeys, err := redis.GetClientKeysExpiringBefore(time.Now().Add(within))
+	if err != nil {
+		return err
+	}
+
+	for _, clientKey := range clientKeys {
+		if err = refreshClientToken(clientKey); err != nil {
+			handleError(err, fmt.Sprintf("Unable to refresh token of client key %s", clientKey), "error")
+		}
+	}
+	return nil
+}
+
+// - IndexExistingTokens adds the accounts connected before the refresher index existed, run once before the first refresh
+func IndexExistingTokens() error {
+	return redis.IndexAllTokenExpiries()
+}
+
+func refreshClientToken(clientKey string) error {
+	tiktokOAuth, err := redis.GetClientByClientKey(clientKey)
+	if err != nil {
+		return err
+	}
+
+	if !tiktokOAuth.RefreshExpiry.IsZero() && tiktokOAuth.RefreshExpiry.Before(time.Now()) {
+		return markReconnectRequired(clientKey, tiktokOAuth, ErrRefreshTokenExpired)
+	}
+
+	version := apiVersionOf(tiktokOAuth)
+	config, err := buildConfig(version)
+	if err != nil {
+		return err
+	}
+
+	token, err := apiClient(version).RefreshToken(ctx, config, tiktokOAuth.RefreshToken)
+	if err != nil {
+		//- network and server errors, rate limits and client misconfiguration are retried on the next run
+		if grantRevoked(err) {
+			return markReconnectRequired(clientKey, tiktokOAuth, err)
+		}
+		return err
+	}
+
+	if err = applyToken(tiktokOAuth, token); err != nil {
+		return err
+	}
+	if !redis.UpdateTiktokByClientKey(clientKey, tiktokOAuth) {
+		return errors.New("Update tiktok token failed")
+	}
+	return nil
+}
+
+// - grantRevoked tells whether tiktok refused the refresh token itself, only then reconnecting is needed
+func grantRevoked(err error) bool {
+	var oauthErr *tiktok.OAuthError
+	return errors.As(err, &oauthErr) && oauthErr.Code == "invalid_grant"
+}
+
+func markReconnectRequired(clientKey string, tiktokOAuth *domain.TiktokOAuth, cause error) error {
+	tiktokOAuth.ReconnectRequired = true
+	if !redis.UpdateTiktokByClientKey(clientKey, tiktokOAuth) {
+		return errors.New("Update tiktok token failed")
+	}
+	handleError(cause, fmt.Sprintf("Client key %s has to reconnect its tiktok account", clientKey), "warn")
+	return nil
+}
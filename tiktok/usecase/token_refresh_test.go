@@ -0,0 +1,32 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"tiktok_api/tiktok"
+)
+
+func TestGrantRevoked(t *testing.T) {
+	cases := []struct {
+		name     string
+		err      error
+		expected bool
+	}{
+		{"revoked refresh token", &tiktok.OAuthError{Code: "invalid_grant"}, true},
+		{"wrapped revoked refresh token", fmt.Errorf("refresh: %w", &tiktok.OAuthError{Code: "invalid_grant"}), true},
+		{"misconfigured client", &tiktok.OAuthError{Code: "invalid_client"}, false},
+		{"rate limited", &tiktok.OAuthError{Code: "rate_limit_exceeded"}, false},
+		{"v1 numeric code", &tiktok.OAuthError{Code: "10002"}, false},
+		{"network error", errors.New("dial tcp: connection refused"), false},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			assert.Equal(t, c.expected, grantRevoked(c.err))
+		})
+	}
+}
@@ -0,0 +1,43 @@
+package usecase
+
+import (
+	"tiktok_api/tiktok"
+	"tiktok_api/tiktok/repository/redis"
+)
+
+// - get basic profile of the connected tiktok account
+func TiktokUserInfo(clientKey string) (*tiktok.UserInfo, error) {
+	tiktokOAuth, err := getConnectedClient(clientKey)
+	if err != nil {
+		return nil, err
+	}
+
+	//- priority to show on redis rather than call to tiktok api for 24 hours
+	isUserInfoExist, err := redis.IsUserInfoExist(clientKey)
+	if err != nil {
+		handleError(err, "Error when call IsUserInfoExist", "error")
+		return nil, err
+	}
+	if isUserInfoExist {
+		userInfo, err := redis.GetUserInfo(clientKey)
+		if err == nil {
+			return userInfo, nil
+		}
+	}
+
+	//- if not exist => call to tiktok api to get user info
+	userInfo, err := apiClient(apiVersionOf(tiktokOAuth)).RetrieveUserInfo(ctx, buildToken(tiktokOAuth))
+	if err != nil {
+		handleError(err, "Error when call RetrieveUserInfo", "error")
+		return nil, err
+	}
+
+	//- update or cache user info to redis
+	_, err = redis.SaveUserInfo(clientKey, userInfo)
+	if err != nil {
+		handleError(err, "Error when save user info", "error")
+		return nil, err
+	}
+
+	return userInfo, nil
+}
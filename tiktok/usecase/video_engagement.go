@@ -0,0 +1,73 @@
+package usecase
+
+import (
+	"errors"
+	"tiktok_api/tiktok"
+	"tiktok_api/tiktok/repository/redis"
+)
+
+var ErrVideoNotFound = errors.New("tiktok video not found")
+
+// - list videos of the connected account, every video on the page refreshes its cached engagement
+func TiktokVideoList(clientKey string, cursor int64, maxCount int) (*tiktok.VideoList, error) {
+	tiktokOAuth, err := getContentClient(clientKey)
+	if err != nil {
+		return nil, err
+	}
+
+	videoList, err := contentClient().ListVideos(ctx, buildToken(tiktokOAuth), cursor, maxCount)
+	if err != nil {
+		handleError(err, "Error when call ListVideos", "error")
+		return nil, err
+	}
+
+	for i := range videoList.Videos {
+		_, err = redis.SaveVideoEngagementInfo(clientKey, &videoList.Videos[i])
+		if err != nil {
+			handleError(err, "Error when save video engagement information", "error")
+			return nil, err
+		}
+	}
+
+	return videoList, nil
+}
+
+// - get current video engagement
+func TiktokVideoEngagement(clientKey string, videoId string) (*tiktok.Video, error) {
+	tiktokOAuth, err := getContentClient(clientKey)
+	if err != nil {
+		return nil, err
+	}
+
+	//- priority to show on redis rather than call to tiktok api for 24 hours
+	isVideoEngagementExist, err := redis.IsVideoEngagementExist(clientKey, videoId)
+	if err != nil {
+		handleError(err, "Error when call IsVideoEngagementExist", "error")
+		return nil, err
+	}
+	if isVideoEngagementExist {
+		video, err := redis.GetVideoEngagementInfo(clientKey, videoId)
+		if err == nil {
+			return video, nil
+		}
+	}
+
+	//- if not exist => call to tiktok api to get video engagement
+	videos, err := contentClient().QueryVideos(ctx, buildToken(tiktokOAuth), []string{videoId})
+	if err != nil {
+		handleError(err, "Error when call QueryVideos", "error")
+		return nil, err
+	}
+	if len(videos) == 0 {
+		return nil, ErrVideoNotFound
+	}
+
+	//- update or cache video engagement to redis
+	_, err = redis.SaveVideoEngagementInfo(clientKey, &videos[0])
+	if err != nil {
+		handleError(err, "Error when save video engagement information", "error")
+		return nil, err
+	}
+
+	return &videos[0], nil
+}
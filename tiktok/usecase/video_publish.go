@@ -0,0 +1,115 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"tiktok_api/domain"
+	"tiktok_api/tiktok"
+	"tiktok_api/tiktok/repository/redis"
+	"time"
+)
+
+const (
+	MB = 1 << 20 //- 1MB
+
+	uploadChunkSize = 10 * MB
+)
+
+var ErrVideoRejected = errors.New("tiktok rejected the video")
+
+var (
+	//- publishing usually completes within a few seconds for short videos,
+	//- callers can check later through TiktokVideoPublishStatus when it does not
+	publishStatusPollInterval = 3 * time.Second
+	publishStatusPollAttempts = 10
+)
+
+func TiktokVideoUploadFile(video io.ReaderAt, clientKey string, postInfo tiktok.PostInfo, tiktokFileUploadInfo *domain.TiktokFileUploadInfo) (*domain.TiktokFileUploadInfo, error) {
+	tiktokOAuth, err := getContentClient(clientKey)
+	if err != nil {
+		return nil, err
+	}
+	token := buildToken(tiktokOAuth)
+	client := contentClient()
+
+	session, err := client.InitVideoUpload(ctx, token, postInfo, tiktokFileUploadInfo.FileSize, uploadChunkSize)
+	if err != nil {
+		handleError(err, "Error when call InitVideoUpload", "error")
+		return nil, err
+	}
+	tiktokFileUploadInfo.PublishId = session.PublishID
+
+	err = client.UploadVideo(ctx, session, video, tiktokFileUploadInfo.FileContentType)
+	if err != nil {
+		handleError(err, "Error when call UploadVideo", "error")
+		return nil, err
+	}
+
+	//- poll until tiktok finishes processing the post
+	var status *tiktok.PublishStatus
+	for attempt := 0; attempt < publishStatusPollAttempts; attempt++ {
+		status, err = client.FetchPublishStatus(ctx, token, session.PublishID)
+		if err != nil {
+			handleError(err, "Error when call FetchPublishStatus", "error")
+			return nil, err
+		}
+		if status.Status == tiktok.PublishStatusComplete || status.Status == tiktok.PublishStatusFailed {
+			break
+		}
+		time.Sleep(publishStatusPollInterval)
+	}
+
+	//- save tiktok file upload info into redis whatever the final status is
+	applyPublishStatus(tiktokFileUploadInfo, status)
+	_, err = redis.SaveTiktokFileUploadInfo(clientKey, tiktokFileUploadInfo)
+	if err != nil {
+		handleError(err, "Save tiktok upload file failed", "error")
+		return nil, err
+	}
+
+	if status.Status == tiktok.PublishStatusFailed {
+		return tiktokFileUploadInfo, fmt.Errorf("%w: %s", ErrVideoRejected, status.FailReason)
+	}
+	log.Printf("Upload successful! Publish ID: %v, status: %v\n", session.PublishID, status.Status)
+	return tiktokFileUploadInfo, nil
+}
+
+// - refresh the publish status of a previous upload from tiktok
+func TiktokVideoPublishStatus(clientKey string, publishId string) (*domain.TiktokFileUploadInfo, error) {
+	tiktokOAuth, err := getContentClient(clientKey)
+	if err != nil {
+		return nil, err
+	}
+
+	tiktokFileUploadInfo, err := redis.GetTiktokFileUploadInfo(clientKey, publishId)
+	if err != nil {
+		return nil, err
+	}
+
+	//- terminal statuses never change, no need to call tiktok again
+	if tiktokFileUploadInfo.PublishStatus == tiktok.PublishStatusComplete || tiktokFileUploadInfo.PublishStatus == tiktok.PublishStatusFailed {
+		return tiktokFileUploadInfo, nil
+	}
+
+	status, err := contentClient().FetchPublishStatus(ctx, buildToken(tiktokOAuth), publishId)
+	if err != nil {
+		handleError(err, "Error when call FetchPublishStatus", "error")
+		return nil, err
+	}
+
+	applyPublishStatus(tiktokFileUploadInfo, status)
+	_, err = redis.SaveTiktokFileUploadInfo(clientKey, tiktokFileUploadInfo)
+	if err != nil {
+		handleError(err, "Save tiktok upload file failed", "error")
+		return nil, err
+	}
+	return tiktokFileUploadInfo, nil
+}
+
+func applyPublishStatus(tiktokFileUploadInfo *domain.TiktokFileUploadInfo, status *tiktok.PublishStatus) {
+	tiktokFileUploadInfo.PublishStatus = status.Status
+	tiktokFileUploadInfo.FailReason = status.FailReason
+	tiktokFileUploadInfo.PostIds = status.PostIDs
+	tiktokFileUploadInfo.UpdatedAt = time.Now()
+}
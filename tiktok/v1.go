@@ -0,0 +1,263 @@
+package tiktok
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+// The v1 API is deprecated by TikTok, it is only kept so tokens issued before the v2 migration keep working.
+
+// configExchangeV1 exchanges the code on the v1 token endpoint, parameters are sent in the query string.
+func (c *Client) configExchangeV1(ctx context.Context, config *oauth2.Config, code string) (*oauth2.Token, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.endpoint(pathTokenV1), nil)
+	if err != nil {
+		return nil, fmt.Errorf("tiktok-oauth2: ConfigExchange: %w", err)
+	}
+
+	q := req.URL.Query()
+	q.Add("client_key", config.ClientID)
+	q.Add("client_secret", config.ClientSecret)
+	q.Add("code", code)
+	q.Add("grant_type", "authorization_code")
+	req.URL.RawQuery = q.Encode()
+
+	response, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("tiktok-oauth2: ConfigExchange: %w", err)
+	}
+
+	defer response.Body.Close()
+
+	bodyBytes, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return nil, fmt.Errorf("tiktok-oauth2: ConfigExchange: %w", err)
+	}
+
+	var body tokenResponse
+	if err = json.Unmarshal(bodyBytes, &body); err != nil {
+		return nil, fmt.Errorf("tiktok-oauth2: ConfigExchange: %w", err)
+	}
+
+	if body == (tokenResponse{}) {
+		return nil, fmt.Errorf("tiktok-oauth2: ConfigExchange: %w", handleErrorResponse(bodyBytes))
+	}
+
+	token := &oauth2.Token{
+		AccessToken:  body.Data.AccessToken,
+		TokenType:    "Bearer",
+		RefreshToken: body.Data.RefreshToken,
+		Expiry:       time.Now().Add(time.Second * time.Duration(body.Data.ExpiresIn)),
+	}
+
+	if token.AccessToken == "" {
+		return nil, fmt.Errorf("tiktok-oauth2: ConfigExchange: server response missing access_token")
+	}
+
+	tokenExtra := map[string]interface{}{
+		"open_id":            body.Data.OpenID,
+		"scope":              body.Data.Scope,
+		"refresh_expires_in": body.Data.RefreshExpiresIn,
+	}
+
+	return token.WithExtra(tokenExtra), nil
+}
+
+// refreshTokenV1 refreshes the access token on the v1 refresh endpoint, the client secret is not required.
+func (c *Client) refreshTokenV1(ctx context.Context, clientID, refreshToken string) (*oauth2.Token, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.endpoint(pathRefreshV1), nil)
+	if err != nil {
+		return nil, fmt.Errorf("tiktok-oauth2: %w", err)
+	}
+
+	q := req.URL.Query()
+	q.Add("client_key", clientID)
+	q.Add("refresh_token", refreshToken)
+	q.Add("grant_type", "refresh_token")
+	req.URL.RawQuery = q.Encode()
+
+	response, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("tiktok-oauth2: RefreshToken: %w", err)
+	}
+
+	defer response.Body.Close()
+
+	bodyBytes, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return nil, fmt.Errorf("tiktok-oauth2: RefreshToken: %w", err)
+	}
+
+	var body tokenResponse
+	if err = json.Unmarshal(bodyBytes, &body); err != nil {
+		return nil, fmt.Errorf("tiktok-oauth2: RefreshToken: %w", err)
+	}
+
+	if body == (tokenResponse{}) {
+		return nil, fmt.Errorf("tiktok-oauth2: RefreshToken: %w", handleErrorResponse(bodyBytes))
+	}
+
+	token := &oauth2.Token{
+		AccessToken:  body.Data.AccessToken,
+		TokenType:    "Bearer",
+		RefreshToken: body.Data.RefreshToken,
+		Expiry:       time.Now().Add(time.Second * time.Duration(body.Data.ExpiresIn)),
+	}
+
+	if token.AccessToken == "" {
+		return nil, fmt.Errorf("tiktok-oauth2: RefreshToken: server response missing access_token")
+	}
+
+	tokenExtra := map[string]interface{}{
+		"open_id":            body.Data.OpenID,
+		"scope":              body.Data.Scope,
+		"refresh_expires_in": body.Data.RefreshExpiresIn,
+	}
+
+	return token.WithExtra(tokenExtra), nil
+}
+
+// revokeAccessV1 revokes the access token on the v1 revoke endpoint.
+func (c *Client) revokeAccessV1(ctx context.Context, token *oauth2.Token) error {
+	openID, err := OpenIDFromToken(token)
+	if err != nil {
+		return fmt.Errorf("tiktok-oauth2: RevokeAccess: failed to get open_id from token")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.endpoint(pathRevokeV1), nil)
+	if err != nil {
+		return fmt.Errorf("tiktok-oauth2: RevokeAccess: %w", err)
+	}
+
+	q := req.URL.Query()
+	q.Add("access_token", token.AccessToken)
+	q.Add("open_id", openID)
+	req.URL.RawQuery = q.Encode()
+
+	response, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("tiktok-oauth2: RevokeAccess: %w", err)
+	}
+
+	defer response.Body.Close()
+
+	bodyBytes, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return fmt.Errorf("tiktok-oauth2: RevokeAccess: %w", err)
+	}
+
+	var body revokeResponse
+	if err = json.Unmarshal(bodyBytes, &body); err != nil {
+		return fmt.Errorf("tiktok-oauth2: RevokeAccess: %w", err)
+	}
+
+	if body.Message != "success" {
+		return fmt.Errorf("tiktok-oauth2: RevokeAccess: %w", handleErrorResponse(bodyBytes))
+	}
+
+	return nil
+}
+
+// retrieveUserInfoV1 returns the basic user info from the v1 user info endpoint.
+func (c *Client) retrieveUserInfoV1(ctx context.Context, token *oauth2.Token) (*UserInfo, error) {
+	openID, err := OpenIDFromToken(token)
+	if err != nil {
+		return nil, fmt.Errorf("tiktok-oauth2: RetrieveUserInfo: failed to get open_id from token")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.endpoint(pathUserInfoV1), nil)
+	if err != nil {
+		return nil, fmt.Errorf("tiktok-oauth2: RetrieveUserInfo: %w", err)
+	}
+
+	q := req.URL.Query()
+	q.Add("access_token", token.AccessToken)
+	q.Add("open_id", openID)
+	req.URL.RawQuery = q.Encode()
+
+	response, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("tiktok-oauth2: RetrieveUserInfo: %w", err)
+	}
+
+	defer response.Body.Close()
+
+	bodyBytes, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return nil, fmt.Errorf("tiktok-oauth2: RetrieveUserInfo: %w", err)
+	}
+
+	var body userInfoResponse
+	if err = json.Unmarshal(bodyBytes, &body); err != nil {
+		return nil, fmt.Errorf("tiktok-oauth2: RetrieveUserInfo: %w", err)
+	}
+
+	if body == (userInfoResponse{}) {
+		return nil, fmt.Errorf("tiktok-oauth2: RetrieveUserInfo: %w", handleErrorResponse(bodyBytes))
+	}
+
+	return &UserInfo{
+		OpenID:       body.Data.OpenID,
+		UnionID:      body.Data.UnionID,
+		Avatar:       body.Data.Avatar,
+		AvatarLarger: body.Data.AvatarLarger,
+		DisplayName:  body.Data.DisplayName,
+	}, nil
+}
+
+func handleErrorResponse(data []byte) error {
+	var errBody errorResponse
+	if err := json.Unmarshal(data, &errBody); err != nil {
+		return err
+	}
+
+	return &OAuthError{Code: strconv.Itoa(errBody.Data.ErrorCode), Description: errBody.Data.Description}
+}
+
+type userInfoResponse struct {
+	Data struct {
+		OpenID       string `json:"open_id"`
+		UnionID      string `json:"union_id"`
+		Avatar       string `json:"avatar"`
+		AvatarLarger string `json:"avatar_larger"`
+		DisplayName  string `json:"display_name"`
+	}
+}
+
+type tokenResponse struct {
+	Data struct {
+		OpenID           string `json:"open_id"`
+		Scope            string `json:"scope"`
+		AccessToken      string `json:"access_token"`
+		ExpiresIn        int64  `json:"expires_in"`
+		RefreshToken     string `json:"refresh_token"`
+		RefreshExpiresIn int64  `json:"refresh_expires_in"`
+	} `json:"data"`
+}
+
+type revokeResponse struct {
+	Data struct {
+		Captcha     string `json:"captcha"`
+		DescURL     string `json:"desc_url"`
+		Description string `json:"description"`
+		ErrorCode   int    `json:"error_code"`
+		LogID       string `json:"log_id"`
+	}
+	Message string `json:"message"`
+}
+
+type errorResponse struct {
+	Data struct {
+		Captcha     string `json:"captcha"`
+		DescURL     string `json:"desc_url"`
+		Description string `json:"description"`
+		ErrorCode   int    `json:"error_code"`
+	} `json:"data"`
+	Message string `json:"message"`
+}
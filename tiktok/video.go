@@ -0,0 +1,85 @@
+package tiktok
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+
+	"golang.org/x/oauth2"
+)
+
+const (
+	// MaxVideoListCount is the largest page size accepted by the video list endpoint.
+	MaxVideoListCount = 20
+	// MaxVideoQueryCount is the largest number of video ids accepted by the video query endpoint.
+	MaxVideoQueryCount = 20
+
+	videoFields = "id,title,cover_image_url,share_url,create_time,duration,view_count,like_count,comment_count,share_count"
+)
+
+// ListVideos returns a page of the user's public videos, newest first.
+func ListVideos(ctx context.Context, token *oauth2.Token, cursor int64, maxCount int) (*VideoList, error) {
+	return defaultClient.ListVideos(ctx, token, cursor, maxCount)
+}
+
+// QueryVideos returns the given videos of the user with their current engagement counters.
+func QueryVideos(ctx context.Context, token *oauth2.Token, videoIDs []string) ([]Video, error) {
+	return defaultClient.QueryVideos(ctx, token, videoIDs)
+}
+
+// ListVideos returns a page of the user's public videos, newest first.
+// Pass the cursor of the previous page to continue, 0 starts from the most recent video.
+func (c *Client) ListVideos(ctx context.Context, token *oauth2.Token, cursor int64, maxCount int) (*VideoList, error) {
+	if token == nil || token.AccessToken == "" {
+		return nil, fmt.Errorf("tiktok-oauth2: ListVideos: token cannot be empty")
+	}
+
+	if maxCount <= 0 || maxCount > MaxVideoListCount {
+		maxCount = MaxVideoListCount
+	}
+
+	payload := map[string]interface{}{
+		"max_count": maxCount,
+	}
+	if cursor > 0 {
+		payload["cursor"] = cursor
+	}
+
+	var body videoListResponse
+	if err := c.postJSON(ctx, withVideoFields(pathVideoList), token, payload, &body); err != nil {
+		return nil, fmt.Errorf("tiktok-oauth2: ListVideos: %w", err)
+	}
+
+	if body.Data.Videos == nil {
+		body.Data.Videos = []Video{}
+	}
+	return &body.Data, nil
+}
+
+// QueryVideos returns the given videos of the user with their current engagement counters.
+func (c *Client) QueryVideos(ctx context.Context, token *oauth2.Token, videoIDs []string) ([]Video, error) {
+	if token == nil || token.AccessToken == "" {
+		return nil, fmt.Errorf("tiktok-oauth2: QueryVideos: token cannot be empty")
+	}
+
+	if len(videoIDs) == 0 || len(videoIDs) > MaxVideoQueryCount {
+		return nil, fmt.Errorf("tiktok-oauth2: QueryVideos: between 1 and %d video ids are required", MaxVideoQueryCount)
+	}
+
+	payload := map[string]interface{}{
+		"filters": map[string][]string{
+			"video_ids": videoIDs,
+		},
+	}
+
+	var body videoQueryResponse
+	if err := c.postJSON(ctx, withVideoFields(pathVideoQuery), token, payload, &body); err != nil {
+		return nil, fmt.Errorf("tiktok-oauth2: QueryVideos: %w", err)
+	}
+
+	return body.Data.Videos, nil
+}
+
+func withVideoFields(path string) string {
+	return path + "?" + url.Values{"fields": {videoFields}}.Encode()
+}
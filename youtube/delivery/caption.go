@@ -0,0 +1,176 @@
+package router
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"tiktok_api/app/pkg/httpErrors"
+	"tiktok_api/app/utils"
+	"tiktok_api/domain"
+	youtubeUsecase "tiktok_api/youtube/usecase"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+)
+
+const captionField = "caption"
+
+// - readCaptionForm parses the multipart form and returns the caption file, the other fields stay on r.Form
+func readCaptionForm(w http.ResponseWriter, r *http.Request) ([]byte, error) {
+	//- leave some room for the fields around the file
+	r.Body = http.MaxBytesReader(w, r.Body, utils.CaptionMaxSize+MB)
+	if err := r.ParseMultipartForm(utils.CaptionMaxSize + MB); err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			return nil, httpErrors.NewRequestTooLargeError(fmt.Sprintf("caption must be at most %d MB", utils.CaptionMaxSize/MB))
+		}
+		return nil, httpErrors.NewBadRequestError("request must be multipart/form-data")
+	}
+
+	file, _, err := r.FormFile(captionField)
+	if err != nil {
+		return nil, httpErrors.NewBadRequestError(fmt.Sprintf("%s is required", captionField))
+	}
+	defer file.Close()
+
+	caption, err := io.ReadAll(io.LimitReader(file, utils.CaptionMaxSize+1))
+	if err != nil {
+		return nil, httpErrors.NewBadRequestError(err.Error())
+	}
+	if len(caption) > utils.CaptionMaxSize {
+		return nil, httpErrors.NewRequestTooLargeError(fmt.Sprintf("caption must be at most %d MB", utils.CaptionMaxSize/MB))
+	}
+	return caption, nil
+}
+
+func YoutubeCaptionList(w http.ResponseWriter, r *http.Request) error {
+	clientKey := chi.URLParam(r, "clientKey")
+	videoId := chi.URLParam(r, "videoId")
+
+	captions, err := youtubeUsecase.YoutubeCaptionList(clientKey, videoId)
+	if err != nil {
+		return toHttpError(err)
+	}
+
+	render.JSON(w, r, domain.Response{
+		Message:    "Success",
+		Data:       captions,
+		StatusCode: 200,
+	})
+	return nil
+}
+
+// - using form, caption is an SRT or WebVTT file, language, name and is_draft describe the track
+func YoutubeCaptionUpload(w http.ResponseWriter, r *http.Request) error {
+	clientKey := chi.URLParam(r, "clientKey")
+	videoId := chi.URLParam(r, "videoId")
+
+	caption, err := readCaptionForm(w, r)
+	if err != nil {
+		return err
+	}
+	defer r.MultipartForm.RemoveAll()
+
+	payload := &domain.YoutubeCaptionPayload{
+		Language: r.FormValue("language"),
+		Name:     r.FormValue("name"),
+	}
+	if value := r.FormValue("is_draft"); value != "" {
+		if payload.IsDraft, err = strconv.ParseBool(value); err != nil {
+			return httpErrors.NewBadRequestError("is_draft must be a boolean")
+		}
+	}
+
+	track, err := youtubeUsecase.YoutubeCaptionUpload(clientKey, videoId, payload, caption)
+	if err != nil {
+		return toHttpError(err)
+	}
+
+	render.Status(r, http.StatusCreated)
+	render.JSON(w, r, domain.Response{
+		Message:    "Caption uploaded",
+		Data:       track,
+		StatusCode: http.StatusCreated,
+	})
+	return nil
+}
+
+// - using form, caption replaces the file of the track and is_draft is optional
+func YoutubeCaptionReplace(w http.ResponseWriter, r *http.Request) error {
+	clientKey := chi.URLParam(r, "clientKey")
+	videoId := chi.URLParam(r, "videoId")
+	captionId := chi.URLParam(r, "captionId")
+
+	caption, err := readCaptionForm(w, r)
+	if err != nil {
+		return err
+	}
+	defer r.MultipartForm.RemoveAll()
+
+	var isDraft *bool
+	if value := r.FormValue("is_draft"); value != "" {
+		draft, err := strconv.ParseBool(value)
+		if err != nil {
+			return httpErrors.NewBadRequestError("is_draft must be a boolean")
+		}
+		isDraft = &draft
+	}
+
+	track, err := youtubeUsecase.YoutubeCaptionReplace(clientKey, videoId, captionId, caption, isDraft)
+	if err != nil {
+		return toHttpError(err)
+	}
+
+	render.JSON(w, r, domain.Response{
+		Message:    "Caption replaced",
+		Data:       track,
+		StatusCode: 200,
+	})
+	return nil
+}
+
+// - the file is streamed as received from youtube, ?format= converts it to sbv, scc, srt, ttml or vtt
+func YoutubeCaptionDownload(w http.ResponseWriter, r *http.Request) error {
+	clientKey := chi.URLParam(r, "clientKey")
+	videoId := chi.URLParam(r, "videoId")
+	captionId := chi.URLParam(r, "captionId")
+	format := r.URL.Query().Get("format")
+
+	response, err := youtubeUsecase.YoutubeCaptionDownload(clientKey, videoId, captionId, format)
+	if err != nil {
+		return toHttpError(err)
+	}
+	defer response.Body.Close()
+
+	if format == "" {
+		format = "txt"
+	}
+	contentType := response.Header.Get("Content-Type")
+	if contentType == "" {
+		contentType = "text/plain; charset=utf-8"
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", fmt.Sprintf("%s.%s", captionId, format)))
+	w.WriteHeader(http.StatusOK)
+	_, err = io.Copy(w, response.Body)
+	return err
+}
+
+func YoutubeCaptionDelete(w http.ResponseWriter, r *http.Request) error {
+	clientKey := chi.URLParam(r, "clientKey")
+	videoId := chi.URLParam(r, "videoId")
+	captionId := chi.URLParam(r, "captionId")
+
+	if err := youtubeUsecase.YoutubeCaptionDelete(clientKey, videoId, captionId); err != nil {
+		return toHttpError(err)
+	}
+
+	render.JSON(w, r, domain.Response{
+		Message:    "Caption deleted",
+		Data:       captionId,
+		StatusCode: 200,
+	})
+	return nil
+}
@@ -0,0 +1,59 @@
+package router
+
+import (
+	"net/http"
+	"strconv"
+	"tiktok_api/app/pkg/httpErrors"
+	"tiktok_api/domain"
+	youtubeUsecase "tiktok_api/youtube/usecase"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+)
+
+// - the overview is cached in redis, ?refresh=true reads it again from youtube
+func YoutubeChannelOverview(w http.ResponseWriter, r *http.Request) error {
+	clientKey := chi.URLParam(r, "clientKey")
+
+	refresh := false
+	if value := r.URL.Query().Get("refresh"); value != "" {
+		var err error
+		if refresh, err = strconv.ParseBool(value); err != nil {
+			return httpErrors.NewBadRequestError("refresh must be a boolean")
+		}
+	}
+
+	channel, err := youtubeUsecase.YoutubeChannelOverview(clientKey, refresh)
+	if err != nil {
+		return toHttpError(err)
+	}
+
+	render.JSON(w, r, domain.Response{
+		Message:    "Success",
+		Data:       channel,
+		StatusCode: 200,
+	})
+	return nil
+}
+
+// - pages are walked with the next_cursor and prev_cursor of the previous response, limit is at most 50
+func YoutubeChannelUploads(w http.ResponseWriter, r *http.Request) error {
+	clientKey := chi.URLParam(r, "clientKey")
+
+	limit, err := pageLimit(r)
+	if err != nil {
+		return err
+	}
+
+	page, err := youtubeUsecase.YoutubeChannelUploads(clientKey, r.URL.Query().Get("cursor"), limit)
+	if err != nil {
+		return toHttpError(err)
+	}
+
+	render.JSON(w, r, domain.Response{
+		Message:    "Success",
+		Data:       page,
+		StatusCode: 200,
+	})
+	return nil
+}
@@ -0,0 +1,105 @@
+package router
+
+import (
+	"encoding/json"
+	"net/http"
+	"tiktok_api/app/pkg/httpErrors"
+	"tiktok_api/domain"
+	youtubeUsecase "tiktok_api/youtube/usecase"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+)
+
+// - ?video_id= lists the threads of a video, otherwise of ?channel_id= or the client's own channel.
+// - ?since=last only returns threads posted since the previous call with since=last, cursor and limit page through everything.
+func YoutubeCommentThreadList(w http.ResponseWriter, r *http.Request) error {
+	clientKey := chi.URLParam(r, "clientKey")
+	query := r.URL.Query()
+	videoId, channelId := query.Get("video_id"), query.Get("channel_id")
+
+	var page *domain.YoutubeCommentThreadPage
+	var err error
+	switch query.Get("since") {
+	case "":
+		var limit int64
+		if limit, err = pageLimit(r); err != nil {
+			return err
+		}
+		page, err = youtubeUsecase.YoutubeCommentThreadList(clientKey, videoId, channelId, query.Get("cursor"), limit)
+	case "last":
+		page, err = youtubeUsecase.YoutubeCommentThreadListSince(clientKey, videoId, channelId)
+	default:
+		return httpErrors.NewBadRequestError("since must be last")
+	}
+	if err != nil {
+		return toHttpError(err)
+	}
+
+	render.JSON(w, r, domain.Response{
+		Message:    "Success",
+		Data:       page,
+		StatusCode: 200,
+	})
+	return nil
+}
+
+func YoutubeCommentReply(w http.ResponseWriter, r *http.Request) error {
+	clientKey := chi.URLParam(r, "clientKey")
+	commentId := chi.URLParam(r, "commentId")
+
+	payload := &domain.YoutubeCommentReplyPayload{}
+	if err := json.NewDecoder(r.Body).Decode(payload); err != nil {
+		return httpErrors.NewBadRequestError(err.Error())
+	}
+
+	comment, err := youtubeUsecase.YoutubeCommentReply(clientKey, commentId, payload)
+	if err != nil {
+		return toHttpError(err)
+	}
+
+	render.Status(r, http.StatusCreated)
+	render.JSON(w, r, domain.Response{
+		Message:    "Reply posted",
+		Data:       comment,
+		StatusCode: http.StatusCreated,
+	})
+	return nil
+}
+
+func YoutubeCommentModerate(w http.ResponseWriter, r *http.Request) error {
+	clientKey := chi.URLParam(r, "clientKey")
+	commentId := chi.URLParam(r, "commentId")
+
+	payload := &domain.YoutubeCommentModerationPayload{}
+	if err := json.NewDecoder(r.Body).Decode(payload); err != nil {
+		return httpErrors.NewBadRequestError(err.Error())
+	}
+
+	if err := youtubeUsecase.YoutubeCommentModerate(clientKey, commentId, payload); err != nil {
+		return toHttpError(err)
+	}
+
+	render.JSON(w, r, domain.Response{
+		Message:    "Comment moderated",
+		Data:       payload,
+		StatusCode: 200,
+	})
+	return nil
+}
+
+func YoutubeCommentDelete(w http.ResponseWriter, r *http.Request) error {
+	clientKey := chi.URLParam(r, "clientKey")
+	commentId := chi.URLParam(r, "commentId")
+
+	if err := youtubeUsecase.YoutubeCommentDelete(clientKey, commentId); err != nil {
+		return toHttpError(err)
+	}
+
+	render.JSON(w, r, domain.Response{
+		Message:    "Comment deleted",
+		Data:       commentId,
+		StatusCode: 200,
+	})
+	return nil
+}
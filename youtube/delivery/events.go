@@ -0,0 +1,80 @@
+package router
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"tiktok_api/app/pkg/httpErrors"
+	"tiktok_api/domain"
+	youtubeUsecase "tiktok_api/youtube/usecase"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// - comment lines keep proxies from closing an idle stream while youtube is processing
+const eventsHeartbeatInterval = 15 * time.Second
+
+// - UploadJobEvents streams the job as server-sent events, byte progress first, then processing, then the final state
+func UploadJobEvents(w http.ResponseWriter, r *http.Request) error {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		return httpErrors.NewInternalServerError("Streaming is not supported")
+	}
+
+	jobs, err := youtubeUsecase.WatchUploadJob(r.Context(), chi.URLParam(r, "id"))
+	if err != nil {
+		return toHttpError(err)
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	//- nginx buffers responses by default
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(eventsHeartbeatInterval)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case job, ok := <-jobs:
+			if !ok {
+				return nil
+			}
+			if err := writeJobEvent(w, job); err != nil {
+				//- the client went away
+				return nil
+			}
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(w, ": heartbeat\n\n"); err != nil {
+				return nil
+			}
+		}
+		flusher.Flush()
+	}
+}
+
+func writeJobEvent(w http.ResponseWriter, job *domain.UploadJob) error {
+	data, err := json.Marshal(redactUploadJob(job))
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", job.UpdatedAt.UnixMilli(), jobEventName(job), data)
+	return err
+}
+
+func jobEventName(job *domain.UploadJob) string {
+	switch job.Status {
+	case domain.UploadJobUploading:
+		return "progress"
+	case domain.UploadJobProcessing:
+		return "processing"
+	case domain.UploadJobDone:
+		return "done"
+	case domain.UploadJobFailed:
+		return "failed"
+	}
+	return "queued"
+}
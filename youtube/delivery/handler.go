@@ -1,26 +1,31 @@
 package router
 
 import (
-	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"io"
 	"net/http"
+	"net/url"
+	"strconv"
 	"tiktok_api/app/logger"
+	"tiktok_api/app/pkg/httpErrors"
 	"tiktok_api/app/utils"
 	"tiktok_api/domain"
+	"tiktok_api/youtube/repository/redis"
 	youtubeUsecase "tiktok_api/youtube/usecase"
 	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/render"
-	"golang.org/x/exp/slices"
+	"google.golang.org/api/googleapi"
 )
 
 var log = logger.NewLogrusLogger()
 
 const (
 	MB = 1 << 20 //- 1MB
+
+	defaultEngagementHistoryRange = 30 * 24 * time.Hour
 )
 
 // - generate AuthURL from config file
@@ -58,17 +63,91 @@ func MediaUpdate(w http.ResponseWriter, r *http.Request) error {
 	return nil
 }
 
+// - engagement is cached for EngagementMaxAge, Cache-Control and Age tell the client how fresh it is
+// - and a request with a matching If-None-Match is answered with 304 Not Modified
 func YoutubeVideoEngagement(w http.ResponseWriter, r *http.Request) error {
 	clientKey := chi.URLParam(r, "clientKey")
 	videoId := chi.URLParam(r, "videoId")
-	videoStats, _ := youtubeUsecase.YoutubeVideoEngagement(clientKey, videoId)
-	//- Call logic from use case or repository
+	engagement, err := youtubeUsecase.YoutubeVideoEngagement(clientKey, videoId)
+	if err != nil {
+		return toHttpError(err)
+	}
+
+	etag, err := utils.ETag(engagement.Statistics)
+	if err != nil {
+		return httpErrors.NewInternalServerError(err)
+	}
+	w.Header().Set("Cache-Control", fmt.Sprintf("private, max-age=%d", int64(time.Duration(engagement.MaxAge).Seconds())))
+	w.Header().Set("Age", strconv.FormatInt(int64(engagement.Age(time.Now()).Seconds()), 10))
+	w.Header().Set("ETag", etag)
+	if utils.ETagMatch(r.Header.Get("If-None-Match"), etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return nil
+	}
+
 	render.JSON(w, r, domain.Response{
 		Message:    "Success",
-		Data:       videoStats,
+		Data:       engagement.Statistics,
 		StatusCode: 200,
 	})
+	return nil
+}
 
+// - body is {"client_key": "...", "video_ids": [...]}, every id gets a result with status ok, not_found or error
+func YoutubeVideoEngagementBatch(w http.ResponseWriter, r *http.Request) error {
+	payload := &domain.YoutubeEngagementBatchPayload{}
+	if err := json.NewDecoder(r.Body).Decode(payload); err != nil {
+		return httpErrors.NewBadRequestError(err.Error())
+	}
+
+	results, err := youtubeUsecase.YoutubeVideoEngagementBatch(payload)
+	if err != nil {
+		return toHttpError(err)
+	}
+
+	render.JSON(w, r, domain.Response{
+		Message:    "Success",
+		Data:       results,
+		StatusCode: 200,
+	})
+	return nil
+}
+
+// - from and to are RFC 3339 timestamps, the last 30 days by default. interval is hour, day (default), week or a duration like 6h
+func YoutubeVideoEngagementHistory(w http.ResponseWriter, r *http.Request) error {
+	clientKey := chi.URLParam(r, "clientKey")
+	videoId := chi.URLParam(r, "videoId")
+	query := r.URL.Query()
+
+	to := time.Now()
+	if value := query.Get("to"); value != "" {
+		var err error
+		if to, err = time.Parse(time.RFC3339, value); err != nil {
+			return httpErrors.NewBadRequestError("to must be an RFC 3339 timestamp")
+		}
+	}
+	from := to.Add(-defaultEngagementHistoryRange)
+	if value := query.Get("from"); value != "" {
+		var err error
+		if from, err = time.Parse(time.RFC3339, value); err != nil {
+			return httpErrors.NewBadRequestError("from must be an RFC 3339 timestamp")
+		}
+	}
+	interval := query.Get("interval")
+	if interval == "" {
+		interval = "day"
+	}
+
+	history, err := youtubeUsecase.YoutubeVideoEngagementHistory(clientKey, videoId, from, to, interval)
+	if err != nil {
+		return toHttpError(err)
+	}
+
+	render.JSON(w, r, domain.Response{
+		Message:    "Success",
+		Data:       history,
+		StatusCode: 200,
+	})
 	return nil
 }
 
@@ -81,96 +160,90 @@ func contains(s []string, e string) bool {
 	return false
 }
 
-// - using form
+// - using form, the video part is streamed to a temp file instead of memory
 func YoutubeVideoUploadFile(w http.ResponseWriter, r *http.Request) error {
-	message := "Video upload success"
-	//- limit to 5mb per file
-	if err := r.ParseMultipartForm(5 * MB); err != nil {
+	upload, err := receiveMultipartUpload(w, r, "file_upload")
+	if err != nil {
 		fields := logger.Fields{
 			"service": "Youtube",
-			"message": "Error when parse multipart form",
+			"message": "Error when receive multipart upload",
 		}
-		log.Fields(fields).Errorf(err, "Error when parse multipart form")
+		log.Fields(fields).Errorf(err, "Error when receive multipart upload")
 		return err
 	}
+	defer upload.Remove()
 
-	// Limit upload size
-	r.Body = http.MaxBytesReader(w, r.Body, 5*MB) // 5 Mb
+	clientKey := upload.Form.Get("client_key")
 
-	// Get handler for filename, size and headers
-	clientKey := r.FormValue("client_key")
-	file, handler, err := r.FormFile("file_upload")
+	//- video metadata, validated before spending any upload quota
+	metadata, err := metadataFromForm(upload.Form)
 	if err != nil {
-		fields := logger.Fields{
-			"service": "Youtube",
-			"message": fmt.Sprintf("Error when receive form file from request with client key %s", clientKey),
-		}
-		log.Fields(fields).Errorf(err, "Error when receive form file from request")
-		return err
+		return httpErrors.NewBadRequestError(err.Error())
+	}
+	metadata.SetDefaults()
+	if err = metadata.Validate(); err != nil {
+		return httpErrors.NewBadRequestError(err.Error())
 	}
-	defer file.Close()
 
-	// validation media type is video
-	if !slices.Contains(utils.VideoContentType, handler.Header.Get("Content-Type")) {
+	//- the worker pool uploads the video, the temp file now belongs to the job
+	job, err := youtubeUsecase.CreateUploadJob(clientKey, upload.File.Name(), upload.ThumbnailPath(), metadata, upload.Info)
+	if err != nil {
 		fields := logger.Fields{
 			"service": "Youtube",
-			"message": fmt.Sprintf("This file is not video content type with client key %s", clientKey),
+			"message": "Error when create upload job",
 		}
-		log.Fields(fields).Errorf(err, "This file is not video content type")
-		return fmt.Errorf("invalid media type, the file is not a video")
+		log.Fields(fields).Errorf(err, "Error when create upload job")
+		return toHttpError(err)
 	}
+	upload.Detach()
 
-	//- save information to redis
-	ytbFileUploadInfo := &domain.YoutubeFileUploadInfo{
-		FileName:        handler.Filename,
-		FileSize:        handler.Size,
-		FileContentType: handler.Header.Get("Content-Type"),
-		CreatedAt:       time.Now(),
+	message := "Video upload queued"
+	data := map[string]string{
+		"job_id":     job.Id,
+		"client_key": clientKey,
+		"status":     job.Status,
+		"job_url":    fmt.Sprintf("/jobs/%s", job.Id),
+		"events_url": fmt.Sprintf("/uploads/%s/events", job.Id),
 	}
-
-	//- convert to buffer
-	buf := bytes.NewBuffer(nil)
-	if _, err = io.Copy(buf, file); err != nil {
-		fields := logger.Fields{
-			"service": "Youtube",
-			"message": "Error when copy file to buffer",
-		}
-		log.Fields(fields).Errorf(err, "Error when copy file to buffer")
-		return err
+	if job.DeferredUntil != nil {
+		message = "Video upload deferred until the youtube quota is reset"
+		data["deferred_until"] = job.DeferredUntil.Format(time.RFC3339)
 	}
+	render.Status(r, http.StatusAccepted)
+	render.JSON(w, r, domain.Response{
+		Message:    message,
+		Data:       data,
+		StatusCode: http.StatusAccepted,
+	})
 
-	videoId, err := youtubeUsecase.YoutubeVideoUploadFile(buf, clientKey, ytbFileUploadInfo)
-
-	dataResponse := map[string]string{}
-	statusCode := 200
+	return nil
+}
 
+func UploadJobStatus(w http.ResponseWriter, r *http.Request) error {
+	jobId := chi.URLParam(r, "id")
+	job, err := youtubeUsecase.GetUploadJob(jobId)
 	if err != nil {
-		fields := logger.Fields{
-			"service": "Youtube",
-			"message": "Error when Video upload failed",
-		}
-		log.Fields(fields).Errorf(err, "Error when Video upload failed")
-
-		dataResponse["error"] = err.Error()
-		statusCode = 403
-		message = "Video upload failed"
-	} else {
-		dataResponse["video_id"] = videoId
-		dataResponse["client_key"] = clientKey
-		dataResponse["youtube_channel"] = fmt.Sprintf("https://www.youtube.com/watch?v=%s", videoId)
-		dataResponse["precaution"] = "Google Quota for video upload is 6 videos per day for free account"
+		return toHttpError(err)
 	}
-	//- Call logic from use case or repository
-	render.Status(r, statusCode)
+
 	render.JSON(w, r, domain.Response{
-		Message:    message,
-		Data:       dataResponse,
-		StatusCode: statusCode,
+		Message:    "Success",
+		Data:       redactUploadJob(job),
+		StatusCode: 200,
 	})
-
 	return nil
 }
 
+// - redactUploadJob drops what only the server may know, the temp file locations and the client key
+// - which is the credential of the account, anyone holding the job id can read the job
+func redactUploadJob(job *domain.UploadJob) *domain.UploadJob {
+	redacted := *job
+	redacted.ClientKey = ""
+	redacted.FilePath = ""
+	redacted.ThumbnailPath = ""
+	return &redacted
+}
+
 func YoutubeVideoUpload(w http.ResponseWriter, r *http.Request) error {
 	var vp *domain.YoutubeVideoUploadPayload
 	err := json.NewDecoder(r.Body).Decode(&vp)
@@ -179,7 +252,12 @@ func YoutubeVideoUpload(w http.ResponseWriter, r *http.Request) error {
 		return err
 	}
 
-	videoId, err := youtubeUsecase.YoutubeVideoUpload(vp.ClientKey, vp.VideoPath)
+	vp.SetDefaults()
+	if err = vp.Validate(); err != nil {
+		return httpErrors.NewBadRequestError(err.Error())
+	}
+
+	videoId, err := youtubeUsecase.YoutubeVideoUpload(vp.ClientKey, vp.VideoPath, &vp.YoutubeVideoMetadata)
 
 	dataResponse := map[string]string{}
 	statusCode := 200
@@ -187,10 +265,18 @@ func YoutubeVideoUpload(w http.ResponseWriter, r *http.Request) error {
 	if err != nil {
 		dataResponse["error"] = err.Error()
 		statusCode = 403
+		if errors.Is(err, youtubeUsecase.ErrQuotaExceeded) {
+			statusCode = http.StatusTooManyRequests
+		}
+		if errors.Is(err, youtubeUsecase.ErrClientKeyNotFound) {
+			statusCode = http.StatusNotFound
+		}
+		if errors.Is(err, youtubeUsecase.ErrReconnectRequired) {
+			statusCode = http.StatusUnauthorized
+		}
 		message = "Video upload failed"
 	} else {
 		dataResponse["youtube_channel"] = fmt.Sprintf("https://www.youtube.com/watch?v=%s", videoId)
-		dataResponse["precaution"] = "Google Quota for video upload is 6 videos per day for free account"
 	}
 	//- Call logic from use case or repository
 	render.Status(r, statusCode)
@@ -203,6 +289,240 @@ func YoutubeVideoUpload(w http.ResponseWriter, r *http.Request) error {
 	return nil
 }
 
+// - metadataFromForm reads the video metadata fields of the upload form, tags are comma separated
+func metadataFromForm(form url.Values) (*domain.YoutubeVideoMetadata, error) {
+	metadata := &domain.YoutubeVideoMetadata{
+		Title:           form.Get("title"),
+		Description:     form.Get("description"),
+		Tags:            domain.ParseYoutubeTags(form.Get("tags")),
+		CategoryId:      form.Get("category_id"),
+		PrivacyStatus:   form.Get("privacy_status"),
+		DefaultLanguage: form.Get("default_language"),
+		License:         form.Get("license"),
+		PlaylistId:      form.Get("playlist_id"),
+	}
+
+	if value := form.Get("made_for_kids"); value != "" {
+		madeForKids, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("made_for_kids must be a boolean")
+		}
+		metadata.MadeForKids = madeForKids
+	}
+
+	if value := form.Get("embeddable"); value != "" {
+		embeddable, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("embeddable must be a boolean")
+		}
+		metadata.Embeddable = &embeddable
+	}
+
+	if value := form.Get("publish_at"); value != "" {
+		publishAt, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return nil, fmt.Errorf("publish_at must be an RFC 3339 timestamp")
+		}
+		metadata.PublishAt = &publishAt
+	}
+
+	return metadata, nil
+}
+
+func YoutubeScheduledVideoList(w http.ResponseWriter, r *http.Request) error {
+	clientKey := chi.URLParam(r, "clientKey")
+	scheduledVideos, err := youtubeUsecase.YoutubeScheduledVideoList(clientKey)
+	if err != nil {
+		return toHttpError(err)
+	}
+
+	render.JSON(w, r, domain.Response{
+		Message:    "Success",
+		Data:       scheduledVideos,
+		StatusCode: 200,
+	})
+	return nil
+}
+
+func YoutubeVideoReschedule(w http.ResponseWriter, r *http.Request) error {
+	clientKey := chi.URLParam(r, "clientKey")
+	videoId := chi.URLParam(r, "videoId")
+
+	payload := &domain.YoutubeSchedulePayload{}
+	if err := json.NewDecoder(r.Body).Decode(payload); err != nil {
+		return httpErrors.NewBadRequestError("publish_at must be an RFC 3339 timestamp")
+	}
+
+	scheduledVideo, err := youtubeUsecase.YoutubeVideoReschedule(clientKey, videoId, payload.PublishAt)
+	if err != nil {
+		return toHttpError(err)
+	}
+
+	render.JSON(w, r, domain.Response{
+		Message:    "Video rescheduled",
+		Data:       scheduledVideo,
+		StatusCode: 200,
+	})
+	return nil
+}
+
+func YoutubeVideoCancelSchedule(w http.ResponseWriter, r *http.Request) error {
+	clientKey := chi.URLParam(r, "clientKey")
+	videoId := chi.URLParam(r, "videoId")
+
+	if err := youtubeUsecase.YoutubeVideoCancelSchedule(clientKey, videoId); err != nil {
+		return toHttpError(err)
+	}
+
+	render.JSON(w, r, domain.Response{
+		Message: "Video schedule cancelled",
+		Data: map[string]string{
+			"video_id":       videoId,
+			"privacy_status": "private",
+		},
+		StatusCode: 200,
+	})
+	return nil
+}
+
+func YoutubeVideoUpdate(w http.ResponseWriter, r *http.Request) error {
+	clientKey := chi.URLParam(r, "clientKey")
+	videoId := chi.URLParam(r, "videoId")
+
+	payload := &domain.YoutubeVideoUpdatePayload{}
+	if err := json.NewDecoder(r.Body).Decode(payload); err != nil {
+		return httpErrors.NewBadRequestError(err.Error())
+	}
+	if payload.Empty() {
+		return httpErrors.NewBadRequestError("nothing to update")
+	}
+
+	video, err := youtubeUsecase.YoutubeVideoUpdate(clientKey, videoId, payload)
+	if err != nil {
+		return toHttpError(err)
+	}
+
+	render.JSON(w, r, domain.Response{
+		Message:    "Video updated",
+		Data:       video,
+		StatusCode: 200,
+	})
+	return nil
+}
+
+func YoutubeVideoDelete(w http.ResponseWriter, r *http.Request) error {
+	clientKey := chi.URLParam(r, "clientKey")
+	videoId := chi.URLParam(r, "videoId")
+
+	if err := youtubeUsecase.YoutubeVideoDelete(clientKey, videoId); err != nil {
+		return toHttpError(err)
+	}
+
+	render.JSON(w, r, domain.Response{
+		Message:    "Video deleted",
+		Data:       videoId,
+		StatusCode: 200,
+	})
+	return nil
+}
+
+// - returns the engagement polling settings of the client key, the configured defaults when none were saved
+func YoutubeEngagementPollSettings(w http.ResponseWriter, r *http.Request) error {
+	clientKey := chi.URLParam(r, "clientKey")
+
+	settings, err := youtubeUsecase.GetEngagementPollSettings(clientKey)
+	if err != nil {
+		return toHttpError(err)
+	}
+
+	render.JSON(w, r, domain.Response{
+		Message:    "Success",
+		Data:       settings,
+		StatusCode: 200,
+	})
+	return nil
+}
+
+// - body is {"early_interval": "1h", "early_period": "48h", "late_interval": "24h", "disabled": false}
+func YoutubeEngagementPollSettingsUpdate(w http.ResponseWriter, r *http.Request) error {
+	clientKey := chi.URLParam(r, "clientKey")
+
+	settings := &domain.YoutubeEngagementPollSettings{}
+	if err := json.NewDecoder(r.Body).Decode(settings); err != nil {
+		return httpErrors.NewBadRequestError(err.Error())
+	}
+
+	if err := youtubeUsecase.UpdateEngagementPollSettings(clientKey, settings); err != nil {
+		return toHttpError(err)
+	}
+
+	render.JSON(w, r, domain.Response{
+		Message:    "Success",
+		Data:       settings,
+		StatusCode: 200,
+	})
+	return nil
+}
+
+// - quota used today by the google cloud project of the app, reset at midnight Pacific time
+func YoutubeQuotaUsage(w http.ResponseWriter, r *http.Request) error {
+	usage, err := youtubeUsecase.YoutubeQuotaUsage()
+	if err != nil {
+		return httpErrors.NewInternalServerError(err.Error())
+	}
+
+	render.JSON(w, r, domain.Response{
+		Message:    "Success",
+		Data:       usage,
+		StatusCode: 200,
+	})
+	return nil
+}
+
+// - sentinel errors answered with a 404
+var notFoundErrors = []error{
+	youtubeUsecase.ErrClientKeyNotFound,
+	youtubeUsecase.ErrVideoNotFound,
+	youtubeUsecase.ErrPlaylistNotFound,
+	youtubeUsecase.ErrPlaylistItemNotFound,
+	youtubeUsecase.ErrCaptionNotFound,
+	youtubeUsecase.ErrCommentNotFound,
+	youtubeUsecase.ErrChannelNotFound,
+	redis.ErrScheduleNotFound,
+	redis.ErrJobNotFound,
+}
+
+// - toHttpError answers unknown client keys, videos, schedules and jobs with a 404, rejected input with a 400,
+// - accounts to reconnect with a 401, youtube errors with their own status and anything else with a 500
+func toHttpError(err error) error {
+	if errors.Is(err, youtubeUsecase.ErrQuotaExceeded) {
+		return httpErrors.NewTooManyRequestsError(err.Error())
+	}
+	if errors.Is(err, youtubeUsecase.ErrReconnectRequired) {
+		return httpErrors.NewUnauthorizedError(err.Error())
+	}
+	for _, notFound := range notFoundErrors {
+		if errors.Is(err, notFound) {
+			return httpErrors.NewNotFoundError(err.Error())
+		}
+	}
+
+	var validationErr *domain.ValidationError
+	if errors.As(err, &validationErr) {
+		return httpErrors.NewBadRequestError(err.Error())
+	}
+
+	//- client errors of youtube keep their status, its own failures are a bad gateway
+	var apiErr *googleapi.Error
+	if errors.As(err, &apiErr) {
+		if apiErr.Code >= http.StatusBadRequest && apiErr.Code < http.StatusInternalServerError {
+			return httpErrors.NewRestError(apiErr.Code, http.StatusText(apiErr.Code), apiErr.Message)
+		}
+		return httpErrors.NewRestError(http.StatusBadGateway, http.StatusText(http.StatusBadGateway), apiErr.Message)
+	}
+	return httpErrors.NewInternalServerError(err.Error())
+}
+
 func DataRetrieval(w http.ResponseWriter, r *http.Request) error {
 	//- Call logic from use case or repository
 	render.JSON(w, r, domain.Response{
@@ -0,0 +1,175 @@
+package router
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"tiktok_api/app/pkg/httpErrors"
+	"tiktok_api/domain"
+	youtubeUsecase "tiktok_api/youtube/usecase"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+)
+
+// - pageLimit reads ?limit=, zero lets the usecase pick its default page size
+func pageLimit(r *http.Request) (int64, error) {
+	value := r.URL.Query().Get("limit")
+	if value == "" {
+		return 0, nil
+	}
+	limit, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || limit <= 0 {
+		return 0, httpErrors.NewBadRequestError("limit must be a positive number")
+	}
+	return limit, nil
+}
+
+func YoutubePlaylistCreate(w http.ResponseWriter, r *http.Request) error {
+	clientKey := chi.URLParam(r, "clientKey")
+
+	payload := &domain.YoutubePlaylistPayload{}
+	if err := json.NewDecoder(r.Body).Decode(payload); err != nil {
+		return httpErrors.NewBadRequestError(err.Error())
+	}
+
+	playlist, err := youtubeUsecase.YoutubePlaylistCreate(clientKey, payload)
+	if err != nil {
+		return toHttpError(err)
+	}
+
+	render.Status(r, http.StatusCreated)
+	render.JSON(w, r, domain.Response{
+		Message:    "Playlist created",
+		Data:       playlist,
+		StatusCode: http.StatusCreated,
+	})
+	return nil
+}
+
+func YoutubePlaylistUpdate(w http.ResponseWriter, r *http.Request) error {
+	clientKey := chi.URLParam(r, "clientKey")
+	playlistId := chi.URLParam(r, "playlistId")
+
+	payload := &domain.YoutubePlaylistPayload{}
+	if err := json.NewDecoder(r.Body).Decode(payload); err != nil {
+		return httpErrors.NewBadRequestError(err.Error())
+	}
+
+	playlist, err := youtubeUsecase.YoutubePlaylistUpdate(clientKey, playlistId, payload)
+	if err != nil {
+		return toHttpError(err)
+	}
+
+	render.JSON(w, r, domain.Response{
+		Message:    "Playlist updated",
+		Data:       playlist,
+		StatusCode: 200,
+	})
+	return nil
+}
+
+func YoutubePlaylistDelete(w http.ResponseWriter, r *http.Request) error {
+	clientKey := chi.URLParam(r, "clientKey")
+	playlistId := chi.URLParam(r, "playlistId")
+
+	if err := youtubeUsecase.YoutubePlaylistDelete(clientKey, playlistId); err != nil {
+		return toHttpError(err)
+	}
+
+	render.JSON(w, r, domain.Response{
+		Message:    "Playlist deleted",
+		Data:       playlistId,
+		StatusCode: 200,
+	})
+	return nil
+}
+
+// - pages are walked with the next_cursor and prev_cursor of the previous response, limit is at most 50
+func YoutubePlaylistItemList(w http.ResponseWriter, r *http.Request) error {
+	clientKey := chi.URLParam(r, "clientKey")
+	playlistId := chi.URLParam(r, "playlistId")
+
+	limit, err := pageLimit(r)
+	if err != nil {
+		return err
+	}
+
+	page, err := youtubeUsecase.YoutubePlaylistItemList(clientKey, playlistId, r.URL.Query().Get("cursor"), limit)
+	if err != nil {
+		return toHttpError(err)
+	}
+
+	render.JSON(w, r, domain.Response{
+		Message:    "Success",
+		Data:       page,
+		StatusCode: 200,
+	})
+	return nil
+}
+
+func YoutubePlaylistItemAdd(w http.ResponseWriter, r *http.Request) error {
+	clientKey := chi.URLParam(r, "clientKey")
+	playlistId := chi.URLParam(r, "playlistId")
+
+	payload := &domain.YoutubePlaylistItemPayload{}
+	if err := json.NewDecoder(r.Body).Decode(payload); err != nil {
+		return httpErrors.NewBadRequestError(err.Error())
+	}
+
+	item, err := youtubeUsecase.YoutubePlaylistItemAdd(clientKey, playlistId, payload)
+	if err != nil {
+		return toHttpError(err)
+	}
+
+	render.Status(r, http.StatusCreated)
+	render.JSON(w, r, domain.Response{
+		Message:    "Video added to playlist",
+		Data:       item,
+		StatusCode: http.StatusCreated,
+	})
+	return nil
+}
+
+func YoutubePlaylistItemMove(w http.ResponseWriter, r *http.Request) error {
+	clientKey := chi.URLParam(r, "clientKey")
+	playlistId := chi.URLParam(r, "playlistId")
+	itemId := chi.URLParam(r, "itemId")
+
+	payload := &domain.YoutubePlaylistItemPayload{}
+	if err := json.NewDecoder(r.Body).Decode(payload); err != nil {
+		return httpErrors.NewBadRequestError(err.Error())
+	}
+	if payload.Position == nil {
+		return httpErrors.NewBadRequestError("position is required")
+	}
+
+	item, err := youtubeUsecase.YoutubePlaylistItemMove(clientKey, playlistId, itemId, *payload.Position)
+	if err != nil {
+		return toHttpError(err)
+	}
+
+	render.JSON(w, r, domain.Response{
+		Message:    "Playlist item moved",
+		Data:       item,
+		StatusCode: 200,
+	})
+	return nil
+}
+
+func YoutubePlaylistItemRemove(w http.ResponseWriter, r *http.Request) error {
+	clientKey := chi.URLParam(r, "clientKey")
+	playlistId := chi.URLParam(r, "playlistId")
+	itemId := chi.URLParam(r, "itemId")
+
+	if err := youtubeUsecase.YoutubePlaylistItemRemove(clientKey, playlistId, itemId); err != nil {
+		return toHttpError(err)
+	}
+
+	render.JSON(w, r, domain.Response{
+		Message:    "Video removed from playlist",
+		Data:       itemId,
+		StatusCode: 200,
+	})
+	return nil
+}
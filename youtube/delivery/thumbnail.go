@@ -0,0 +1,71 @@
+package router
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"tiktok_api/app/pkg/httpErrors"
+	"tiktok_api/app/utils"
+	"tiktok_api/domain"
+	youtubeUsecase "tiktok_api/youtube/usecase"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+)
+
+// - readThumbnail keeps the image in memory, it is small enough, and checks its size and magic bytes
+func readThumbnail(thumbnail io.Reader) ([]byte, string, error) {
+	data, err := io.ReadAll(io.LimitReader(thumbnail, utils.ThumbnailMaxSize+1))
+	if err != nil {
+		return nil, "", err
+	}
+	if len(data) > utils.ThumbnailMaxSize {
+		return nil, "", httpErrors.NewRequestTooLargeError(fmt.Sprintf("thumbnail must be at most %d MB", utils.ThumbnailMaxSize/MB))
+	}
+	contentType, err := utils.DetectThumbnailType(data)
+	if err != nil {
+		return nil, "", httpErrors.NewBadRequestError(err.Error())
+	}
+	return data, contentType, nil
+}
+
+// - using form, client_key and the thumbnail image
+func YoutubeVideoSetThumbnail(w http.ResponseWriter, r *http.Request) error {
+	videoId := chi.URLParam(r, "videoId")
+
+	//- leave some room for the client key around the image
+	r.Body = http.MaxBytesReader(w, r.Body, utils.ThumbnailMaxSize+MB)
+	if err := r.ParseMultipartForm(utils.ThumbnailMaxSize + MB); err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			return httpErrors.NewRequestTooLargeError(fmt.Sprintf("thumbnail must be at most %d MB", utils.ThumbnailMaxSize/MB))
+		}
+		return httpErrors.NewBadRequestError("request must be multipart/form-data")
+	}
+	defer r.MultipartForm.RemoveAll()
+
+	thumbnail, _, err := r.FormFile(thumbnailField)
+	if err != nil {
+		return httpErrors.NewBadRequestError(fmt.Sprintf("%s is required", thumbnailField))
+	}
+	defer thumbnail.Close()
+
+	data, contentType, err := readThumbnail(thumbnail)
+	if err != nil {
+		return err
+	}
+
+	thumbnails, err := youtubeUsecase.YoutubeVideoSetThumbnail(r.FormValue("client_key"), videoId, bytes.NewReader(data), contentType)
+	if err != nil {
+		return toHttpError(err)
+	}
+
+	render.JSON(w, r, domain.Response{
+		Message:    "Thumbnail updated",
+		Data:       thumbnails,
+		StatusCode: 200,
+	})
+	return nil
+}
@@ -0,0 +1,175 @@
+package router
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"tiktok_api/app/pkg/httpErrors"
+	"tiktok_api/app/utils"
+	"tiktok_api/domain"
+	"tiktok_api/youtube/repository/redis"
+	youtubeUsecase "tiktok_api/youtube/usecase"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"golang.org/x/exp/slices"
+)
+
+const (
+	TusVersion    = "1.0.0"
+	TusExtensions = "creation,termination,expiration"
+
+	tusChunkContentType = "application/offset+octet-stream"
+)
+
+// - TusResumable stamps every response with the protocol version and rejects clients speaking another one
+func TusResumable(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Tus-Resumable", TusVersion)
+		if r.Method != http.MethodOptions && r.Header.Get("Tus-Resumable") != TusVersion {
+			w.Header().Set("Tus-Version", TusVersion)
+			w.WriteHeader(http.StatusPreconditionFailed)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func TusOptions(w http.ResponseWriter, r *http.Request) error {
+	w.Header().Set("Tus-Version", TusVersion)
+	w.Header().Set("Tus-Extension", TusExtensions)
+	w.Header().Set("Tus-Max-Size", strconv.FormatInt(uploadMaxSize(), 10))
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+// - TusCreateUpload validates the video metadata up front, so a client never sends hundreds of MB for a rejected upload.
+// - Upload-Metadata carries client_key, filename, filetype and the same video fields as /youtube/video/file.
+func TusCreateUpload(w http.ResponseWriter, r *http.Request) error {
+	length, err := strconv.ParseInt(r.Header.Get("Upload-Length"), 10, 64)
+	if err != nil || length <= 0 {
+		return httpErrors.NewBadRequestError("Upload-Length must be a positive integer")
+	}
+	if length > uploadMaxSize() {
+		return httpErrors.NewRequestTooLargeError(fmt.Sprintf("video must be at most %d MB", uploadMaxSize()/MB))
+	}
+
+	rawMetadata, err := utils.ParseTusMetadata(r.Header.Get("Upload-Metadata"))
+	if err != nil {
+		return httpErrors.NewBadRequestError(err.Error())
+	}
+	form := url.Values{}
+	for key, value := range rawMetadata {
+		form.Set(key, value)
+	}
+
+	clientKey := form.Get("client_key")
+	if clientKey == "" {
+		return httpErrors.NewBadRequestError("client_key is required in Upload-Metadata")
+	}
+	contentType := form.Get("filetype")
+	if !slices.Contains(utils.VideoContentType, contentType) {
+		return httpErrors.NewBadRequestError("invalid media type, the file is not a video")
+	}
+
+	metadata, err := metadataFromForm(form)
+	if err != nil {
+		return httpErrors.NewBadRequestError(err.Error())
+	}
+	metadata.SetDefaults()
+	if err = metadata.Validate(); err != nil {
+		return httpErrors.NewBadRequestError(err.Error())
+	}
+
+	ytbFileUploadInfo := &domain.YoutubeFileUploadInfo{
+		FileName:        form.Get("filename"),
+		FileSize:        length,
+		FileContentType: contentType,
+		CreatedAt:       time.Now(),
+	}
+	upload, err := youtubeUsecase.CreateTusUpload(clientKey, length, rawMetadata, metadata, ytbFileUploadInfo)
+	if err != nil {
+		return toTusError(err)
+	}
+
+	w.Header().Set("Location", fmt.Sprintf("/uploads/%s", upload.Id))
+	setTusUploadExpires(w, upload)
+	w.WriteHeader(http.StatusCreated)
+	return nil
+}
+
+func TusUploadOffset(w http.ResponseWriter, r *http.Request) error {
+	upload, err := youtubeUsecase.GetTusUpload(chi.URLParam(r, "id"))
+	if err != nil {
+		return toTusError(err)
+	}
+
+	w.Header().Set("Upload-Offset", strconv.FormatInt(upload.Offset, 10))
+	w.Header().Set("Upload-Length", strconv.FormatInt(upload.Length, 10))
+	//- client_key is the credential of the account, anyone holding the upload url can send a HEAD
+	metadata := make(map[string]string, len(upload.Metadata))
+	for key, value := range upload.Metadata {
+		if key != "client_key" {
+			metadata[key] = value
+		}
+	}
+	if len(metadata) > 0 {
+		w.Header().Set("Upload-Metadata", utils.EncodeTusMetadata(metadata))
+	}
+	setTusUploadExpires(w, upload)
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusOK)
+	return nil
+}
+
+// - TusWriteChunk appends the request body, once the last byte arrives the upload is queued as job /jobs/{id}
+func TusWriteChunk(w http.ResponseWriter, r *http.Request) error {
+	if r.Header.Get("Content-Type") != tusChunkContentType {
+		return httpErrors.NewRestError(http.StatusUnsupportedMediaType, fmt.Sprintf("Content-Type must be %s", tusChunkContentType), nil)
+	}
+	offset, err := strconv.ParseInt(r.Header.Get("Upload-Offset"), 10, 64)
+	if err != nil || offset < 0 {
+		return httpErrors.NewBadRequestError("Upload-Offset must be a non negative integer")
+	}
+
+	upload, err := youtubeUsecase.WriteTusChunk(chi.URLParam(r, "id"), offset, r.Body)
+	if err != nil {
+		return toTusError(err)
+	}
+
+	w.Header().Set("Upload-Offset", strconv.FormatInt(upload.Offset, 10))
+	setTusUploadExpires(w, upload)
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+func TusTerminateUpload(w http.ResponseWriter, r *http.Request) error {
+	if err := youtubeUsecase.TerminateTusUpload(chi.URLParam(r, "id")); err != nil {
+		return toTusError(err)
+	}
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+// - setTusUploadExpires tells the client until when an unfinished upload can be resumed
+func setTusUploadExpires(w http.ResponseWriter, upload *domain.TusUpload) {
+	if upload.Completed() || upload.ExpiresAt.IsZero() {
+		return
+	}
+	w.Header().Set("Upload-Expires", upload.ExpiresAt.UTC().Format(http.TimeFormat))
+}
+
+// - tus clients decide whether to resume, retry or give up from the status code alone
+func toTusError(err error) error {
+	switch {
+	case errors.Is(err, redis.ErrTusUploadNotFound):
+		return httpErrors.NewNotFoundError(err.Error())
+	case errors.Is(err, youtubeUsecase.ErrTusOffsetMismatch), errors.Is(err, youtubeUsecase.ErrTusUploadCompleted):
+		return httpErrors.NewRestError(http.StatusConflict, err.Error(), nil)
+	case errors.Is(err, youtubeUsecase.ErrTusUploadLocked):
+		return httpErrors.NewRestError(http.StatusLocked, err.Error(), nil)
+	}
+	return toHttpError(err)
+}
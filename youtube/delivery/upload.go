@@ -0,0 +1,194 @@
+package router
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"os"
+	"tiktok_api/app/pkg/httpErrors"
+	"tiktok_api/app/utils"
+	"tiktok_api/domain"
+	"time"
+
+	"github.com/spf13/viper"
+	"golang.org/x/exp/slices"
+)
+
+const (
+	defaultUploadMaxSize = 2048 * MB
+	//- plain form fields never need more than this
+	maxFormFieldSize = 64 << 10
+	//- optional image part applied as the video thumbnail
+	thumbnailField = "thumbnail"
+)
+
+// - multipartUpload is an upload form whose video part has been streamed to a temp file
+type multipartUpload struct {
+	Form      url.Values
+	File      *os.File
+	Info      *domain.YoutubeFileUploadInfo
+	Thumbnail *os.File
+}
+
+// - Remove closes and deletes the temp files, safe to call more than once
+func (u *multipartUpload) Remove() {
+	for _, file := range []*os.File{u.File, u.Thumbnail} {
+		if file != nil {
+			file.Close()
+			os.Remove(file.Name())
+		}
+	}
+	u.File = nil
+	u.Thumbnail = nil
+}
+
+// - Detach closes the temp files and keeps them on disk for whoever took over their path
+func (u *multipartUpload) Detach() {
+	for _, file := range []*os.File{u.File, u.Thumbnail} {
+		if file != nil {
+			file.Close()
+		}
+	}
+	u.File = nil
+	u.Thumbnail = nil
+}
+
+// - ThumbnailPath returns where the optional thumbnail was saved, empty when none was sent
+func (u *multipartUpload) ThumbnailPath() string {
+	if u.Thumbnail == nil {
+		return ""
+	}
+	return u.Thumbnail.Name()
+}
+
+func uploadMaxSize() int64 {
+	if size := viper.GetInt64("YOUTUBE.UPLOAD_MAX_SIZE_MB"); size > 0 {
+		return size * MB
+	}
+	return defaultUploadMaxSize
+}
+
+// - receiveMultipartUpload streams the request part by part so the video is never held in memory.
+// - The video part named fileField is written to a temp file, every other part is kept as a form value.
+func receiveMultipartUpload(w http.ResponseWriter, r *http.Request, fileField string) (*multipartUpload, error) {
+	maxSize := uploadMaxSize()
+	//- leave some room for the form fields around the video
+	r.Body = http.MaxBytesReader(w, r.Body, maxSize+MB)
+
+	reader, err := r.MultipartReader()
+	if err != nil {
+		return nil, httpErrors.NewBadRequestError("request must be multipart/form-data")
+	}
+
+	upload := &multipartUpload{Form: url.Values{}}
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			upload.Remove()
+			return nil, uploadError(err, maxSize)
+		}
+
+		if part.FormName() == fileField && part.FileName() != "" {
+			if upload.File != nil {
+				upload.Remove()
+				return nil, httpErrors.NewBadRequestError(fmt.Sprintf("only one %s is allowed", fileField))
+			}
+			if err = upload.saveFile(part, maxSize); err != nil {
+				upload.Remove()
+				return nil, uploadError(err, maxSize)
+			}
+			continue
+		}
+
+		if part.FormName() == thumbnailField && part.FileName() != "" {
+			if upload.Thumbnail != nil {
+				upload.Remove()
+				return nil, httpErrors.NewBadRequestError(fmt.Sprintf("only one %s is allowed", thumbnailField))
+			}
+			if err = upload.saveThumbnail(part); err != nil {
+				upload.Remove()
+				return nil, uploadError(err, maxSize)
+			}
+			continue
+		}
+
+		value, err := io.ReadAll(io.LimitReader(part, maxFormFieldSize))
+		if err != nil {
+			upload.Remove()
+			return nil, uploadError(err, maxSize)
+		}
+		upload.Form.Add(part.FormName(), string(value))
+	}
+
+	if upload.File == nil {
+		return nil, httpErrors.NewBadRequestError(fmt.Sprintf("%s is required", fileField))
+	}
+	return upload, nil
+}
+
+func (u *multipartUpload) saveFile(part *multipart.Part, maxSize int64) error {
+	contentType := part.Header.Get("Content-Type")
+	if !slices.Contains(utils.VideoContentType, contentType) {
+		return httpErrors.NewBadRequestError("invalid media type, the file is not a video")
+	}
+
+	file, err := os.CreateTemp(viper.GetString("YOUTUBE.UPLOAD_TMP_DIR"), "youtube-upload-*")
+	if err != nil {
+		return httpErrors.NewInternalServerError(err.Error())
+	}
+	u.File = file
+
+	size, err := io.Copy(file, io.LimitReader(part, maxSize+1))
+	if err != nil {
+		return err
+	}
+	if size > maxSize {
+		return &http.MaxBytesError{Limit: maxSize}
+	}
+	if _, err = file.Seek(0, io.SeekStart); err != nil {
+		return httpErrors.NewInternalServerError(err.Error())
+	}
+
+	u.Info = &domain.YoutubeFileUploadInfo{
+		FileName:        part.FileName(),
+		FileSize:        size,
+		FileContentType: contentType,
+		CreatedAt:       time.Now(),
+	}
+	return nil
+}
+
+func (u *multipartUpload) saveThumbnail(part *multipart.Part) error {
+	data, _, err := readThumbnail(part)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.CreateTemp(viper.GetString("YOUTUBE.UPLOAD_TMP_DIR"), "youtube-thumbnail-*")
+	if err != nil {
+		return httpErrors.NewInternalServerError(err.Error())
+	}
+	u.Thumbnail = file
+	if _, err = file.Write(data); err != nil {
+		return httpErrors.NewInternalServerError(err.Error())
+	}
+	return nil
+}
+
+func uploadError(err error, maxSize int64) error {
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		return httpErrors.NewRequestTooLargeError(fmt.Sprintf("video must be at most %d MB", maxSize/MB))
+	}
+	var restErr httpErrors.Error
+	if errors.As(err, &restErr) {
+		return restErr
+	}
+	return httpErrors.NewBadRequestError(err.Error())
+}
@@ -5,10 +5,13 @@ import (
 	"encoding/json"
 	"fmt"
 	"math/rand"
+	"strconv"
 	"tiktok_api/app/logger"
 	"tiktok_api/domain"
 	"tiktok_api/domain/dbInstance"
 	"time"
+
+	"github.com/redis/go-redis/v9"
 )
 
 // - youtube require to collect client_id, client_secret, project_id from client
@@ -16,6 +19,11 @@ var clientInstance = dbInstance.GetRedisInstance()
 var log = logger.NewLogrusLogger()
 var ctx = context.Background()
 
+const (
+	//- sorted set of client keys scored by access token expiry, read by the token refresher
+	TOKEN_EXPIRY_KEY = "youtube_token_expiry"
+)
+
 const charset = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
 
 func generateRandomString(length int) string {
@@ -88,9 +96,82 @@ func UpdateYoutubeByClientKey(clientKey string, youtubeOAuth *domain.YoutubeOAut
 		return false
 	}
 
+	indexTokenExpiry(clientKey, youtubeOAuth)
 	return true
 }
 
+// - indexTokenExpiry keeps the refresher index in sync, only records that can still be refreshed are listed
+func indexTokenExpiry(clientKey string, youtubeOAuth *domain.YoutubeOAuth) {
+	var err error
+	if !tokenIndexable(youtubeOAuth) {
+		err = clientInstance.ZRem(ctx, TOKEN_EXPIRY_KEY, clientKey).Err()
+	} else {
+		err = clientInstance.ZAdd(ctx, TOKEN_EXPIRY_KEY, redis.Z{
+			Score:  float64(youtubeOAuth.Expiry.Unix()),
+			Member: clientKey,
+		}).Err()
+	}
+	if err != nil {
+		handleError(err, fmt.Sprintf("Error when index token expiry of key %s", clientKey), "error")
+	}
+}
+
+func tokenIndexable(youtubeOAuth *domain.YoutubeOAuth) bool {
+	return youtubeOAuth.RefreshToken != "" && !youtubeOAuth.ReconnectRequired && !youtubeOAuth.Expiry.IsZero()
+}
+
+// - IndexAllTokenExpiries adds to the refresher index the records saved before it existed, indexed records are left as they are
+func IndexAllTokenExpiries() error {
+	//- youtube client keys are the 12 characters made by generateRandomString
+	iter := clientInstance.ScanType(ctx, 0, "????????????", 100, "string").Iterator()
+	for iter.Next(ctx) {
+		clientKey := iter.Val()
+		val, err := clientInstance.Get(ctx, clientKey).Result()
+		if err != nil {
+			continue
+		}
+		youtubeOAuth := &domain.YoutubeOAuth{}
+		if err = json.Unmarshal([]byte(val), youtubeOAuth); err != nil || !tokenIndexable(youtubeOAuth) {
+			continue
+		}
+		err = clientInstance.ZAddNX(ctx, TOKEN_EXPIRY_KEY, redis.Z{
+			Score:  float64(youtubeOAuth.Expiry.Unix()),
+			Member: clientKey,
+		}).Err()
+		if err != nil {
+			handleError(err, fmt.Sprintf("Error when index token expiry of key %s", clientKey), "error")
+			return err
+		}
+	}
+	if err := iter.Err(); err != nil {
+		handleError(err, "Error when scan youtube clients", "error")
+		return err
+	}
+	return nil
+}
+
+// - GetClientKeysExpiringBefore returns the client keys whose access token expires before the given time
+func GetClientKeysExpiringBefore(before time.Time) ([]string, error) {
+	clientKeys, err := clientInstance.ZRangeByScore(ctx, TOKEN_EXPIRY_KEY, &redis.ZRangeBy{
+		Min: "-inf",
+		Max: strconv.FormatInt(before.Unix(), 10),
+	}).Result()
+	if err != nil {
+		handleError(err, fmt.Sprintf("Error when read %s from redis", TOKEN_EXPIRY_KEY), "error")
+		return nil, err
+	}
+	return clientKeys, nil
+}
+
+// - RemoveTokenExpiry drops a client key from the refresher index, used when its record is gone
+func RemoveTokenExpiry(clientKey string) error {
+	err := clientInstance.ZRem(ctx, TOKEN_EXPIRY_KEY, clientKey).Err()
+	if err != nil {
+		handleError(err, fmt.Sprintf("Error when remove key %s from %s", clientKey, TOKEN_EXPIRY_KEY), "error")
+	}
+	return err
+}
+
 func handleError(err error, message string, errorType string) {
 	fields := logger.Fields{
 		"service": "Youtube",
@@ -0,0 +1,177 @@
+package redis
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"tiktok_api/domain"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	TUS_KEY_PREFIX = "youtube_tus"
+	//- sorted set of unfinished upload ids scored by the time they expire, read by the reaper
+	TUS_EXPIRING_KEY = "youtube_tus_expiring"
+	//- an expired upload outlives its Upload-Expires by this much so the reaper still finds its file
+	TUS_REAP_GRACE = time.Hour
+	//- a PATCH holds the lock while it writes and keeps extending it, a crashed request frees it after this
+	TUS_LOCK_EXPIRATION = 30 * time.Second
+)
+
+var ErrTusUploadNotFound = errors.New("upload not found")
+
+// - the lock is only extended or released by the request holding it, never by one whose lock expired
+var extendTusLockScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+return 0
+`)
+
+var releaseTusLockScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+
+func tusUploadKey(uploadId string) string {
+	return fmt.Sprintf("%s_%s", TUS_KEY_PREFIX, uploadId)
+}
+
+func tusLockKey(uploadId string) string {
+	return fmt.Sprintf("%s_%s_lock", TUS_KEY_PREFIX, uploadId)
+}
+
+// - NewTusUploadId returns an unguessable id, knowing the upload url is enough to write to the upload
+func NewTusUploadId() (string, error) {
+	uploadId, err := randomHex(16)
+	if err != nil {
+		handleError(err, "Error when generate tus upload id", "error")
+		return "", err
+	}
+	return uploadId, nil
+}
+
+func randomHex(length int) (string, error) {
+	b := make([]byte, length)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+func SaveTusUpload(upload *domain.TusUpload) (bool, error) {
+	upload.UpdatedAt = time.Now()
+	byte, err := json.Marshal(&upload)
+	if err != nil {
+		handleError(err, fmt.Sprintf("Error when json.Marshal into redis at key %s", tusUploadKey(upload.Id)), "error")
+		return false, err
+	}
+
+	//- completed uploads only stay around to answer HEAD requests of clients resuming late
+	_, err = clientInstance.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		if upload.Completed() {
+			pipe.Set(ctx, tusUploadKey(upload.Id), string(byte), FINISHED_JOB_EXPIRATION)
+			pipe.ZRem(ctx, TUS_EXPIRING_KEY, upload.Id)
+			return nil
+		}
+		expiration := time.Until(upload.ExpiresAt) + TUS_REAP_GRACE
+		if expiration < TUS_REAP_GRACE {
+			expiration = TUS_REAP_GRACE
+		}
+		pipe.Set(ctx, tusUploadKey(upload.Id), string(byte), expiration)
+		pipe.ZAdd(ctx, TUS_EXPIRING_KEY, redis.Z{Score: float64(upload.ExpiresAt.Unix()), Member: upload.Id})
+		return nil
+	})
+	if err != nil {
+		handleError(err, "Error when save tus upload into redis", "error")
+		return false, err
+	}
+	return true, nil
+}
+
+func GetTusUpload(uploadId string) (*domain.TusUpload, error) {
+	val, err := clientInstance.Get(ctx, tusUploadKey(uploadId)).Result()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, ErrTusUploadNotFound
+		}
+		handleError(err, "Error when get tus upload from redis", "error")
+		return nil, err
+	}
+
+	upload := &domain.TusUpload{}
+	err = json.Unmarshal([]byte(val), &upload)
+	if err != nil {
+		handleError(err, "Error when unmarshal tus upload from redis", "error")
+		return nil, err
+	}
+	return upload, nil
+}
+
+func DeleteTusUpload(uploadId string) error {
+	_, err := clientInstance.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Del(ctx, tusUploadKey(uploadId), tusLockKey(uploadId))
+		pipe.ZRem(ctx, TUS_EXPIRING_KEY, uploadId)
+		return nil
+	})
+	if err != nil {
+		handleError(err, "Error when delete tus upload from redis", "error")
+	}
+	return err
+}
+
+// - GetExpiredTusUploadIds returns the ids of unfinished uploads which expired before the given time
+func GetExpiredTusUploadIds(before time.Time) ([]string, error) {
+	uploadIds, err := clientInstance.ZRangeByScore(ctx, TUS_EXPIRING_KEY, &redis.ZRangeBy{
+		Min: "-inf",
+		Max: strconv.FormatInt(before.Unix(), 10),
+	}).Result()
+	if err != nil {
+		handleError(err, fmt.Sprintf("Error when read %s from redis", TUS_EXPIRING_KEY), "error")
+		return nil, err
+	}
+	return uploadIds, nil
+}
+
+// - LockTusUpload returns the owner of the lock, empty when another request is already writing to the upload
+func LockTusUpload(uploadId string) (string, error) {
+	owner, err := randomHex(16)
+	if err != nil {
+		handleError(err, "Error when generate tus upload lock owner", "error")
+		return "", err
+	}
+	locked, err := clientInstance.SetNX(ctx, tusLockKey(uploadId), owner, TUS_LOCK_EXPIRATION).Result()
+	if err != nil {
+		handleError(err, "Error when lock tus upload in redis", "error")
+		return "", err
+	}
+	if !locked {
+		return "", nil
+	}
+	return owner, nil
+}
+
+// - ExtendTusUploadLock returns false when the lock expired and may be held by another request
+func ExtendTusUploadLock(uploadId string, owner string) (bool, error) {
+	extended, err := extendTusLockScript.Run(ctx, clientInstance, []string{tusLockKey(uploadId)}, owner, TUS_LOCK_EXPIRATION.Milliseconds()).Int()
+	if err != nil {
+		handleError(err, "Error when extend tus upload lock in redis", "error")
+		return false, err
+	}
+	return extended == 1, nil
+}
+
+func UnlockTusUpload(uploadId string, owner string) error {
+	err := releaseTusLockScript.Run(ctx, clientInstance, []string{tusLockKey(uploadId)}, owner).Err()
+	if err != nil {
+		handleError(err, "Error when unlock tus upload in redis", "error")
+	}
+	return err
+}
@@ -0,0 +1,83 @@
+package redis
+
+import (
+	"testing"
+	"time"
+
+	"tiktok_api/domain"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTusUploadLock(t *testing.T) {
+	mr := useMiniredis(t)
+
+	owner, err := LockTusUpload("upload-1")
+	assert.Nil(t, err)
+	assert.NotEmpty(t, owner)
+
+	//- a second request cannot write while the lock is held
+	other, err := LockTusUpload("upload-1")
+	assert.Nil(t, err)
+	assert.Empty(t, other)
+
+	//- only the owner extends or releases the lock
+	extended, err := ExtendTusUploadLock("upload-1", "someone-else")
+	assert.Nil(t, err)
+	assert.False(t, extended)
+	extended, err = ExtendTusUploadLock("upload-1", owner)
+	assert.Nil(t, err)
+	assert.True(t, extended)
+	assert.Nil(t, UnlockTusUpload("upload-1", "someone-else"))
+	assert.True(t, mr.Exists(tusLockKey("upload-1")))
+	assert.Nil(t, UnlockTusUpload("upload-1", owner))
+	assert.False(t, mr.Exists(tusLockKey("upload-1")))
+
+	//- an expired lock is free for the next request and lost for its previous owner
+	owner, _ = LockTusUpload("upload-1")
+	mr.FastForward(TUS_LOCK_EXPIRATION)
+	next, err := LockTusUpload("upload-1")
+	assert.Nil(t, err)
+	assert.NotEmpty(t, next)
+	extended, err = ExtendTusUploadLock("upload-1", owner)
+	assert.Nil(t, err)
+	assert.False(t, extended)
+}
+
+func TestSaveTusUpload(t *testing.T) {
+	mr := useMiniredis(t)
+
+	now := time.Now()
+	upload := &domain.TusUpload{Id: "upload-1", Length: 100, Offset: 40, ExpiresAt: now.Add(time.Hour)}
+	_, err := SaveTusUpload(upload)
+	assert.Nil(t, err)
+
+	saved, err := GetTusUpload("upload-1")
+	assert.Nil(t, err)
+	assert.Equal(t, int64(40), saved.Offset)
+	expired, err := GetExpiredTusUploadIds(now.Add(2 * time.Hour))
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"upload-1"}, expired)
+	expired, err = GetExpiredTusUploadIds(now)
+	assert.Nil(t, err)
+	assert.Empty(t, expired)
+
+	//- a completed upload leaves the reaper index and is kept like a finished job
+	upload.Offset = upload.Length
+	_, err = SaveTusUpload(upload)
+	assert.Nil(t, err)
+	saved, err = GetTusUpload("upload-1")
+	assert.Nil(t, err)
+	assert.Equal(t, int64(100), saved.Offset)
+	expired, err = GetExpiredTusUploadIds(now.Add(2 * time.Hour))
+	assert.Nil(t, err)
+	assert.Empty(t, expired)
+	assert.Equal(t, FINISHED_JOB_EXPIRATION, mr.TTL(tusUploadKey("upload-1")))
+
+	_, err = LockTusUpload("upload-1")
+	assert.Nil(t, err)
+	assert.Nil(t, DeleteTusUpload("upload-1"))
+	_, err = GetTusUpload("upload-1")
+	assert.ErrorIs(t, err, ErrTusUploadNotFound)
+	assert.False(t, mr.Exists(tusLockKey("upload-1")))
+}
@@ -0,0 +1,250 @@
+package redis
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"tiktok_api/domain"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	JOB_KEY_PREFIX = "youtube_job"
+	//- reliable queue: every worker moves a job id from the queue to its own processing list while working on it,
+	//- the list of a worker whose heartbeat expired is moved back to the queue by any instance
+	JOB_QUEUE_KEY             = "youtube_jobs_queue"
+	JOB_PROCESSING_KEY_PREFIX = "youtube_jobs_processing"
+	UPLOAD_WORKERS_KEY        = "youtube_upload_workers"
+	UPLOAD_WORKER_KEY_PREFIX  = "youtube_upload_worker"
+	UPLOAD_WORKER_EXPIRATION  = time.Minute
+	//- sorted set of job ids waiting for the youtube quota to reset, scored by the time they can run again
+	JOB_DEFERRED_KEY = "youtube_jobs_deferred"
+
+	FINISHED_JOB_EXPIRATION = 7 * 24 * time.Hour
+)
+
+var ErrJobNotFound = errors.New("upload job not found")
+
+func jobKey(jobId string) string {
+	return fmt.Sprintf("%s_%s", JOB_KEY_PREFIX, jobId)
+}
+
+func jobProcessingKey(workerId string) string {
+	return fmt.Sprintf("%s_%s", JOB_PROCESSING_KEY_PREFIX, workerId)
+}
+
+// - the heartbeat of a worker, it expires when the instance running the worker is gone
+func uploadWorkerKey(workerId string) string {
+	return fmt.Sprintf("%s_%s", UPLOAD_WORKER_KEY_PREFIX, workerId)
+}
+
+// - every saved state of a job is also published here for the upload events stream
+func jobEventsChannel(jobId string) string {
+	return fmt.Sprintf("%s_%s_events", JOB_KEY_PREFIX, jobId)
+}
+
+// - NewJobId returns an unguessable id, knowing the id of a job or a tus upload is enough to follow it
+func NewJobId() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		handleError(err, "Error when generate job id", "error")
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// - SaveUploadJob stores the job state, finished jobs are kept for a week
+func SaveUploadJob(job *domain.UploadJob) (bool, error) {
+	job.UpdatedAt = time.Now()
+	byte, err := json.Marshal(&job)
+	if err != nil {
+		handleError(err, fmt.Sprintf("Error when json.Marshal into redis at key %s", jobKey(job.Id)), "error")
+		return false, err
+	}
+
+	expiration := time.Duration(0)
+	if job.Finished() {
+		expiration = FINISHED_JOB_EXPIRATION
+	}
+	err = clientInstance.Set(ctx, jobKey(job.Id), string(byte), expiration).Err()
+	if err != nil {
+		handleError(err, "Error when save upload job into redis", "error")
+		return false, err
+	}
+
+	//- nobody may be listening, a failed publish never fails the job
+	if err = clientInstance.Publish(ctx, jobEventsChannel(job.Id), string(byte)).Err(); err != nil {
+		handleError(err, "Error when publish upload job into redis", "warn")
+	}
+	return true, nil
+}
+
+// - SubscribeUploadJob listens to the states published by SaveUploadJob, the caller closes the subscription
+func SubscribeUploadJob(jobId string) (*redis.PubSub, error) {
+	pubsub := clientInstance.Subscribe(ctx, jobEventsChannel(jobId))
+	//- wait for the confirmation so no state saved after this call is missed
+	if _, err := pubsub.Receive(ctx); err != nil {
+		pubsub.Close()
+		handleError(err, "Error when subscribe upload job in redis", "error")
+		return nil, err
+	}
+	return pubsub, nil
+}
+
+func GetUploadJob(jobId string) (*domain.UploadJob, error) {
+	val, err := clientInstance.Get(ctx, jobKey(jobId)).Result()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, ErrJobNotFound
+		}
+		handleError(err, "Error when get upload job from redis", "error")
+		return nil, err
+	}
+
+	job := &domain.UploadJob{}
+	err = json.Unmarshal([]byte(val), &job)
+	if err != nil {
+		handleError(err, "Error when unmarshal upload job from redis", "error")
+		return nil, err
+	}
+	return job, nil
+}
+
+func EnqueueUploadJob(jobId string) error {
+	err := clientInstance.LPush(ctx, JOB_QUEUE_KEY, jobId).Err()
+	if err != nil {
+		handleError(err, "Error when enqueue upload job into redis", "error")
+	}
+	return err
+}
+
+// - NewUploadWorkerId returns a random id, a worker gets a new one every time the app starts
+func NewUploadWorkerId() (string, error) {
+	workerId, err := randomHex(8)
+	if err != nil {
+		handleError(err, "Error when generate upload worker id", "error")
+	}
+	return workerId, err
+}
+
+// - RenewUploadWorker registers the worker and extends its heartbeat, it has to be called more often than UPLOAD_WORKER_EXPIRATION
+func RenewUploadWorker(workerId string) error {
+	_, err := clientInstance.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.SAdd(ctx, UPLOAD_WORKERS_KEY, workerId)
+		pipe.Set(ctx, uploadWorkerKey(workerId), time.Now().Unix(), UPLOAD_WORKER_EXPIRATION)
+		return nil
+	})
+	if err != nil {
+		handleError(err, fmt.Sprintf("Error when renew upload worker %s", workerId), "error")
+	}
+	return err
+}
+
+// - DequeueUploadJob waits up to timeout for a job id, an empty id means the queue stayed empty
+func DequeueUploadJob(workerId string, timeout time.Duration) (string, error) {
+	jobId, err := clientInstance.BLMove(ctx, JOB_QUEUE_KEY, jobProcessingKey(workerId), "RIGHT", "LEFT", timeout).Result()
+	if errors.Is(err, redis.Nil) {
+		return "", nil
+	}
+	if err != nil {
+		handleError(err, "Error when dequeue upload job from redis", "error")
+		return "", err
+	}
+	return jobId, nil
+}
+
+// - AckUploadJob removes a finished job id from the processing list of the worker
+func AckUploadJob(workerId string, jobId string) error {
+	err := clientInstance.LRem(ctx, jobProcessingKey(workerId), 0, jobId).Err()
+	if err != nil {
+		handleError(err, "Error when ack upload job in redis", "error")
+	}
+	return err
+}
+
+// - RequeueInterruptedUploadJobs moves the job ids of workers whose heartbeat expired back to the queue,
+// - jobs of live workers on this or any other instance are left alone
+func RequeueInterruptedUploadJobs() ([]string, error) {
+	workerIds, err := clientInstance.SMembers(ctx, UPLOAD_WORKERS_KEY).Result()
+	if err != nil {
+		handleError(err, fmt.Sprintf("Error when read %s from redis", UPLOAD_WORKERS_KEY), "error")
+		return nil, err
+	}
+
+	requeued := []string{}
+	for _, workerId := range workerIds {
+		alive, err := clientInstance.Exists(ctx, uploadWorkerKey(workerId)).Result()
+		if err != nil {
+			handleError(err, fmt.Sprintf("Error when check upload worker %s", workerId), "error")
+			return requeued, err
+		}
+		if alive > 0 {
+			continue
+		}
+
+		//- every id is moved by a single LMOVE, instances requeueing the same worker never queue a job twice
+		for {
+			jobId, err := clientInstance.LMove(ctx, jobProcessingKey(workerId), JOB_QUEUE_KEY, "RIGHT", "RIGHT").Result()
+			if errors.Is(err, redis.Nil) {
+				break
+			}
+			if err != nil {
+				handleError(err, fmt.Sprintf("Error when requeue upload jobs of worker %s", workerId), "error")
+				return requeued, err
+			}
+			requeued = append(requeued, jobId)
+		}
+		if err = clientInstance.SRem(ctx, UPLOAD_WORKERS_KEY, workerId).Err(); err != nil {
+			handleError(err, fmt.Sprintf("Error when remove upload worker %s", workerId), "error")
+			return requeued, err
+		}
+	}
+	return requeued, nil
+}
+
+// - DeferUploadJob keeps the job out of the queue until the given time
+func DeferUploadJob(jobId string, until time.Time) error {
+	err := clientInstance.ZAdd(ctx, JOB_DEFERRED_KEY, redis.Z{
+		Score:  float64(until.Unix()),
+		Member: jobId,
+	}).Err()
+	if err != nil {
+		handleError(err, fmt.Sprintf("Error when defer upload job %s", jobId), "error")
+	}
+	return err
+}
+
+// - RequeueDeferredUploadJobs moves the deferred jobs due before the given time back to the queue
+func RequeueDeferredUploadJobs(before time.Time) ([]string, error) {
+	jobIds, err := clientInstance.ZRangeByScore(ctx, JOB_DEFERRED_KEY, &redis.ZRangeBy{
+		Min: "-inf",
+		Max: strconv.FormatInt(before.Unix(), 10),
+	}).Result()
+	if err != nil {
+		handleError(err, fmt.Sprintf("Error when read %s from redis", JOB_DEFERRED_KEY), "error")
+		return nil, err
+	}
+
+	requeued := []string{}
+	for _, jobId := range jobIds {
+		//- only the instance which removed the id queues it, so a job is never queued twice
+		removed, err := clientInstance.ZRem(ctx, JOB_DEFERRED_KEY, jobId).Result()
+		if err != nil {
+			handleError(err, fmt.Sprintf("Error when requeue upload job %s", jobId), "error")
+			return requeued, err
+		}
+		if removed == 0 {
+			continue
+		}
+		if err = EnqueueUploadJob(jobId); err != nil {
+			return requeued, err
+		}
+		requeued = append(requeued, jobId)
+	}
+	return requeued, nil
+}
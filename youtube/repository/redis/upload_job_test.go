@@ -0,0 +1,102 @@
+package redis
+
+import (
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+)
+
+// - useMiniredis points the repository at a fresh miniredis until the test ends
+func useMiniredis(t *testing.T) *miniredis.Miniredis {
+	mr := miniredis.RunT(t)
+	previous := clientInstance
+	clientInstance = redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() {
+		clientInstance.Close()
+		clientInstance = previous
+	})
+	return mr
+}
+
+func TestUploadJobQueue(t *testing.T) {
+	mr := useMiniredis(t)
+
+	assert.Nil(t, EnqueueUploadJob("job-1"))
+	assert.Nil(t, EnqueueUploadJob("job-2"))
+
+	//- jobs are taken in the order they were queued and held in the list of the worker
+	jobId, err := DequeueUploadJob("worker-1", time.Second)
+	assert.Nil(t, err)
+	assert.Equal(t, "job-1", jobId)
+	processing, _ := mr.List(jobProcessingKey("worker-1"))
+	assert.Equal(t, []string{"job-1"}, processing)
+	queued, _ := mr.List(JOB_QUEUE_KEY)
+	assert.Equal(t, []string{"job-2"}, queued)
+
+	assert.Nil(t, AckUploadJob("worker-1", "job-1"))
+	assert.False(t, mr.Exists(jobProcessingKey("worker-1")))
+
+	jobId, err = DequeueUploadJob("worker-1", time.Second)
+	assert.Nil(t, err)
+	assert.Equal(t, "job-2", jobId)
+}
+
+func TestRequeueInterruptedUploadJobs(t *testing.T) {
+	mr := useMiniredis(t)
+
+	assert.Nil(t, RenewUploadWorker("live"))
+	assert.Nil(t, RenewUploadWorker("dead"))
+	assert.Nil(t, EnqueueUploadJob("job-1"))
+	assert.Nil(t, EnqueueUploadJob("job-2"))
+	jobId, _ := DequeueUploadJob("live", time.Second)
+	assert.Equal(t, "job-1", jobId)
+	jobId, _ = DequeueUploadJob("dead", time.Second)
+	assert.Equal(t, "job-2", jobId)
+
+	//- nothing is requeued while every worker keeps its heartbeat
+	requeued, err := RequeueInterruptedUploadJobs()
+	assert.Nil(t, err)
+	assert.Empty(t, requeued)
+
+	mr.Del(uploadWorkerKey("dead"))
+	requeued, err = RequeueInterruptedUploadJobs()
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"job-2"}, requeued)
+
+	queued, _ := mr.List(JOB_QUEUE_KEY)
+	assert.Equal(t, []string{"job-2"}, queued)
+	processing, _ := mr.List(jobProcessingKey("live"))
+	assert.Equal(t, []string{"job-1"}, processing)
+	workers, _ := mr.Members(UPLOAD_WORKERS_KEY)
+	assert.Equal(t, []string{"live"}, workers)
+
+	//- the heartbeat expires when the worker stops renewing it
+	mr.FastForward(UPLOAD_WORKER_EXPIRATION)
+	requeued, err = RequeueInterruptedUploadJobs()
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"job-1"}, requeued)
+}
+
+func TestRequeueDeferredUploadJobs(t *testing.T) {
+	mr := useMiniredis(t)
+
+	now := time.Now()
+	assert.Nil(t, DeferUploadJob("job-due", now.Add(-time.Minute)))
+	assert.Nil(t, DeferUploadJob("job-later", now.Add(time.Hour)))
+
+	requeued, err := RequeueDeferredUploadJobs(now)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"job-due"}, requeued)
+	queued, _ := mr.List(JOB_QUEUE_KEY)
+	assert.Equal(t, []string{"job-due"}, queued)
+	deferred, _ := mr.ZMembers(JOB_DEFERRED_KEY)
+	assert.Equal(t, []string{"job-later"}, deferred)
+
+	//- a job already requeued is never queued twice
+	requeued, err = RequeueDeferredUploadJobs(now)
+	assert.Nil(t, err)
+	assert.Empty(t, requeued)
+}
@@ -0,0 +1,55 @@
+package redis
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"tiktok_api/domain"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	CHANNEL_KEY_PREFIX = "youtube_channel"
+)
+
+var ErrChannelNotCached = errors.New("youtube channel not cached")
+
+func channelKey(clientKey string) string {
+	return fmt.Sprintf("%s_%s", CHANNEL_KEY_PREFIX, clientKey)
+}
+
+func SaveChannel(clientKey string, channel *domain.YoutubeChannel, expiration time.Duration) (bool, error) {
+	byte, err := json.Marshal(&channel)
+	if err != nil {
+		handleError(err, fmt.Sprintf("Error when json.Marshal into redis at key %s", channelKey(clientKey)), "error")
+		return false, err
+	}
+
+	err = clientInstance.Set(ctx, channelKey(clientKey), string(byte), expiration).Err()
+	if err != nil {
+		handleError(err, "Error when save youtube channel into redis", "error")
+		return false, err
+	}
+	return true, nil
+}
+
+func GetChannel(clientKey string) (*domain.YoutubeChannel, error) {
+	val, err := clientInstance.Get(ctx, channelKey(clientKey)).Result()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, ErrChannelNotCached
+		}
+		handleError(err, "Error when get youtube channel from redis", "error")
+		return nil, err
+	}
+
+	channel := &domain.YoutubeChannel{}
+	err = json.Unmarshal([]byte(val), &channel)
+	if err != nil {
+		handleError(err, "Error when unmarshal youtube channel from redis", "error")
+		return nil, err
+	}
+	return channel, nil
+}
@@ -0,0 +1,61 @@
+package redis
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"tiktok_api/domain"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	COMMENT_KEY_PREFIX = "youtube_comments"
+)
+
+// - commentKey is a hash of fetch scope (video or channel) to the watermark of its incremental fetch
+func commentKey(clientKey string) string {
+	return fmt.Sprintf("%s_%s", COMMENT_KEY_PREFIX, clientKey)
+}
+
+// - GetCommentWatermark returns a zero watermark when the scope was never fetched
+func GetCommentWatermark(clientKey string, scope string) (*domain.YoutubeCommentWatermark, error) {
+	val, err := clientInstance.HGet(ctx, commentKey(clientKey), scope).Result()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return &domain.YoutubeCommentWatermark{}, nil
+		}
+		handleError(err, "Error when get youtube comment watermark from redis", "error")
+		return nil, err
+	}
+
+	watermark := &domain.YoutubeCommentWatermark{}
+	if !strings.HasPrefix(val, "{") {
+		//- saved as a bare publish time before the ids at the boundary were kept
+		watermark.PublishedAt, err = time.Parse(time.RFC3339Nano, val)
+	} else {
+		err = json.Unmarshal([]byte(val), watermark)
+	}
+	if err != nil {
+		handleError(err, fmt.Sprintf("Value of youtube comment watermark %s failed to parse", scope), "error")
+		return nil, err
+	}
+	return watermark, nil
+}
+
+func SaveCommentWatermark(clientKey string, scope string, watermark *domain.YoutubeCommentWatermark) (bool, error) {
+	byte, err := json.Marshal(watermark)
+	if err != nil {
+		handleError(err, fmt.Sprintf("Error when json.Marshal youtube comment watermark %s", scope), "error")
+		return false, err
+	}
+
+	err = clientInstance.HSet(ctx, commentKey(clientKey), scope, string(byte)).Err()
+	if err != nil {
+		handleError(err, "Error when save youtube comment watermark into redis", "error")
+		return false, err
+	}
+	return true, nil
+}
@@ -0,0 +1,68 @@
+package redis
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"tiktok_api/domain"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	ENGAGEMENT_HISTORY_KEY_PREFIX = "youtube_engagement_history"
+	//- snapshots older than this are dropped whenever a new one is saved
+	ENGAGEMENT_HISTORY_RETENTION = 365 * 24 * time.Hour
+)
+
+// - engagementHistoryKey is a sorted set of YoutubeEngagementSnapshot scored by snapshot time in milliseconds
+func engagementHistoryKey(clientKey string, videoId string) string {
+	return fmt.Sprintf("%s_%s", ENGAGEMENT_HISTORY_KEY_PREFIX, videoKey(clientKey, videoId))
+}
+
+func SaveEngagementSnapshot(clientKey string, videoId string, snapshot *domain.YoutubeEngagementSnapshot) (bool, error) {
+	byte, err := json.Marshal(&snapshot)
+	if err != nil {
+		handleError(err, fmt.Sprintf("Error when json.Marshal into redis at key %s", engagementHistoryKey(clientKey, videoId)), "error")
+		return false, err
+	}
+
+	key := engagementHistoryKey(clientKey, videoId)
+	_, err = clientInstance.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.ZAdd(ctx, key, redis.Z{
+			Score:  float64(snapshot.At.UnixMilli()),
+			Member: string(byte),
+		})
+		pipe.ZRemRangeByScore(ctx, key, "-inf", "("+strconv.FormatInt(snapshot.At.Add(-ENGAGEMENT_HISTORY_RETENTION).UnixMilli(), 10))
+		return nil
+	})
+	if err != nil {
+		handleError(err, "Error when save youtube engagement snapshot into redis", "error")
+		return false, err
+	}
+	return true, nil
+}
+
+// - GetEngagementSnapshots returns the snapshots taken between from and to, oldest first
+func GetEngagementSnapshots(clientKey string, videoId string, from time.Time, to time.Time) ([]*domain.YoutubeEngagementSnapshot, error) {
+	values, err := clientInstance.ZRangeByScore(ctx, engagementHistoryKey(clientKey, videoId), &redis.ZRangeBy{
+		Min: strconv.FormatInt(from.UnixMilli(), 10),
+		Max: strconv.FormatInt(to.UnixMilli(), 10),
+	}).Result()
+	if err != nil {
+		handleError(err, "Error when get youtube engagement snapshots from redis", "error")
+		return nil, err
+	}
+
+	snapshots := make([]*domain.YoutubeEngagementSnapshot, 0, len(values))
+	for _, value := range values {
+		snapshot := &domain.YoutubeEngagementSnapshot{}
+		if err = json.Unmarshal([]byte(value), snapshot); err != nil {
+			handleError(err, "Error when unmarshal youtube engagement snapshot from redis", "error")
+			return nil, err
+		}
+		snapshots = append(snapshots, snapshot)
+	}
+	return snapshots, nil
+}
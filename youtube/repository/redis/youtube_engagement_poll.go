@@ -0,0 +1,113 @@
+package redis
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"tiktok_api/domain"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	//- sorted set of clientKey_videoId scored by the time the engagement of the video is due again
+	ENGAGEMENT_POLL_KEY = "youtube_engagement_poll"
+	//- hash of client key to YoutubeEngagementPollSettings, client keys without an entry use the configured defaults
+	ENGAGEMENT_POLL_SETTINGS_KEY = "youtube_engagement_poll_settings"
+)
+
+// - ScheduleEngagementPoll sets when the video is polled next, replacing any earlier schedule
+func ScheduleEngagementPoll(clientKey string, videoId string, at time.Time) error {
+	err := clientInstance.ZAdd(ctx, ENGAGEMENT_POLL_KEY, redis.Z{
+		Score:  float64(at.Unix()),
+		Member: videoKey(clientKey, videoId),
+	}).Err()
+	if err != nil {
+		handleError(err, fmt.Sprintf("Error when schedule engagement poll of video %s", videoId), "error")
+		return err
+	}
+	return nil
+}
+
+// - AddEngagementPoll schedules the video only when it is not scheduled yet
+func AddEngagementPoll(clientKey string, videoId string, at time.Time) error {
+	err := clientInstance.ZAddNX(ctx, ENGAGEMENT_POLL_KEY, redis.Z{
+		Score:  float64(at.Unix()),
+		Member: videoKey(clientKey, videoId),
+	}).Err()
+	if err != nil {
+		handleError(err, fmt.Sprintf("Error when add engagement poll of video %s", videoId), "error")
+		return err
+	}
+	return nil
+}
+
+func RemoveEngagementPoll(clientKey string, videoId string) error {
+	err := clientInstance.ZRem(ctx, ENGAGEMENT_POLL_KEY, videoKey(clientKey, videoId)).Err()
+	if err != nil {
+		handleError(err, fmt.Sprintf("Error when remove engagement poll of video %s", videoId), "error")
+		return err
+	}
+	return nil
+}
+
+// - GetDueEngagementPolls returns at most limit videos due before the given time, grouped by client key
+func GetDueEngagementPolls(before time.Time, limit int64) (map[string][]string, error) {
+	members, err := clientInstance.ZRangeByScore(ctx, ENGAGEMENT_POLL_KEY, &redis.ZRangeBy{
+		Min:   "-inf",
+		Max:   strconv.FormatInt(before.Unix(), 10),
+		Count: limit,
+	}).Result()
+	if err != nil {
+		handleError(err, fmt.Sprintf("Error when read %s from redis", ENGAGEMENT_POLL_KEY), "error")
+		return nil, err
+	}
+
+	due := map[string][]string{}
+	for _, member := range members {
+		//- client keys are alphanumeric, the video id is everything after the first underscore
+		clientKey, videoId, ok := strings.Cut(member, "_")
+		if !ok {
+			continue
+		}
+		due[clientKey] = append(due[clientKey], videoId)
+	}
+	return due, nil
+}
+
+// - GetEngagementPollSettings returns nil when the client key uses the configured defaults
+func GetEngagementPollSettings(clientKey string) (*domain.YoutubeEngagementPollSettings, error) {
+	val, err := clientInstance.HGet(ctx, ENGAGEMENT_POLL_SETTINGS_KEY, clientKey).Result()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, nil
+		}
+		handleError(err, "Error when get engagement poll settings from redis", "error")
+		return nil, err
+	}
+
+	settings := &domain.YoutubeEngagementPollSettings{}
+	if err = json.Unmarshal([]byte(val), settings); err != nil {
+		handleError(err, "Error when unmarshal engagement poll settings from redis", "error")
+		return nil, err
+	}
+	return settings, nil
+}
+
+func SaveEngagementPollSettings(clientKey string, settings *domain.YoutubeEngagementPollSettings) (bool, error) {
+	byte, err := json.Marshal(settings)
+	if err != nil {
+		handleError(err, fmt.Sprintf("Error when json.Marshal into redis at key %s", ENGAGEMENT_POLL_SETTINGS_KEY), "error")
+		return false, err
+	}
+
+	err = clientInstance.HSet(ctx, ENGAGEMENT_POLL_SETTINGS_KEY, clientKey, string(byte)).Err()
+	if err != nil {
+		handleError(err, "Error when save engagement poll settings into redis", "error")
+		return false, err
+	}
+	return true, nil
+}
@@ -0,0 +1,64 @@
+package redis
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const QUOTA_KEY_PREFIX = "youtube_quota"
+
+// - the usage of a day is kept one more day after the reset so it can still be looked at
+const quotaRetention = 24 * time.Hour
+
+// - reserveQuotaScript adds ARGV[1] units to the usage in KEYS[1] unless that would go over the budget in ARGV[2],
+// - it answers whether the units were reserved and the usage after the call
+var reserveQuotaScript = redis.NewScript(`
+local used = tonumber(redis.call("GET", KEYS[1]) or "0")
+local cost = tonumber(ARGV[1])
+if used + cost > tonumber(ARGV[2]) then
+	return {0, used}
+end
+used = redis.call("INCRBY", KEYS[1], cost)
+redis.call("EXPIREAT", KEYS[1], ARGV[3])
+return {1, used}
+`)
+
+func quotaKey(project string, day string) string {
+	return fmt.Sprintf("%s_%s_%s", QUOTA_KEY_PREFIX, project, day)
+}
+
+// - ReserveQuota counts cost units on the usage of the day unless the budget would be exceeded
+func ReserveQuota(project string, day string, cost int64, budget int64, resetAt time.Time) (bool, int64, error) {
+	expireAt := resetAt.Add(quotaRetention).Unix()
+	result, err := reserveQuotaScript.Run(ctx, clientInstance, []string{quotaKey(project, day)}, cost, budget, expireAt).Int64Slice()
+	if err != nil {
+		handleError(err, fmt.Sprintf("Error when reserve youtube quota of project %s", project), "error")
+		return false, 0, err
+	}
+	return result[0] == 1, result[1], nil
+}
+
+func GetQuotaUsage(project string, day string) (int64, error) {
+	used, err := clientInstance.Get(ctx, quotaKey(project, day)).Int64()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return 0, nil
+		}
+		handleError(err, fmt.Sprintf("Error when get youtube quota of project %s", project), "error")
+		return 0, err
+	}
+	return used, nil
+}
+
+// - ExhaustQuota marks the budget of the day as used up, youtube refused a call because the project ran out of quota
+func ExhaustQuota(project string, day string, budget int64, resetAt time.Time) error {
+	err := clientInstance.Set(ctx, quotaKey(project, day), budget, time.Until(resetAt.Add(quotaRetention))).Err()
+	if err != nil {
+		handleError(err, fmt.Sprintf("Error when exhaust youtube quota of project %s", project), "error")
+		return err
+	}
+	return nil
+}
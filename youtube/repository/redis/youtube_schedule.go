@@ -0,0 +1,118 @@
+package redis
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"tiktok_api/domain"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	SCHEDULE_KEY_PREFIX = "youtube_schedule"
+)
+
+var ErrScheduleNotFound = errors.New("youtube scheduled video not found")
+
+// - scheduleKey is a sorted set of video ids scored by publish time, used for ordering and pruning
+func scheduleKey(clientKey string) string {
+	return fmt.Sprintf("%s_%s", SCHEDULE_KEY_PREFIX, clientKey)
+}
+
+// - scheduleInfoKey is a hash of video id to YoutubeScheduledVideo
+func scheduleInfoKey(clientKey string) string {
+	return fmt.Sprintf("%s_%s_info", SCHEDULE_KEY_PREFIX, clientKey)
+}
+
+func SaveScheduledVideo(clientKey string, scheduledVideo *domain.YoutubeScheduledVideo) (bool, error) {
+	scheduledVideo.UpdatedAt = time.Now()
+	byte, err := json.Marshal(&scheduledVideo)
+	if err != nil {
+		handleError(err, fmt.Sprintf("Error when json.Marshal into redis at key %s", scheduleInfoKey(clientKey)), "error")
+		return false, err
+	}
+
+	_, err = clientInstance.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.ZAdd(ctx, scheduleKey(clientKey), redis.Z{
+			Score:  float64(scheduledVideo.PublishAt.Unix()),
+			Member: scheduledVideo.VideoId,
+		})
+		pipe.HSet(ctx, scheduleInfoKey(clientKey), scheduledVideo.VideoId, string(byte))
+		return nil
+	})
+	if err != nil {
+		handleError(err, "Error when save youtube scheduled video into redis", "error")
+		return false, err
+	}
+	return true, nil
+}
+
+func GetScheduledVideo(clientKey string, videoId string) (*domain.YoutubeScheduledVideo, error) {
+	val, err := clientInstance.HGet(ctx, scheduleInfoKey(clientKey), videoId).Result()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, ErrScheduleNotFound
+		}
+		handleError(err, "Error when get youtube scheduled video from redis", "error")
+		return nil, err
+	}
+
+	scheduledVideo := &domain.YoutubeScheduledVideo{}
+	err = json.Unmarshal([]byte(val), &scheduledVideo)
+	if err != nil {
+		handleError(err, "Error when unmarshal youtube scheduled video from redis", "error")
+		return nil, err
+	}
+	return scheduledVideo, nil
+}
+
+// - GetUpcomingScheduledVideos returns the videos still waiting to be published, soonest first.
+// - Videos youtube has already published are dropped on the way.
+func GetUpcomingScheduledVideos(clientKey string) ([]*domain.YoutubeScheduledVideo, error) {
+	now := strconv.FormatInt(time.Now().Unix(), 10)
+	published, err := clientInstance.ZRangeByScore(ctx, scheduleKey(clientKey), &redis.ZRangeBy{Min: "-inf", Max: now}).Result()
+	if err != nil {
+		handleError(err, "Error when get published youtube videos from redis", "error")
+		return nil, err
+	}
+	for _, videoId := range published {
+		if err = DeleteScheduledVideo(clientKey, videoId); err != nil {
+			return nil, err
+		}
+	}
+
+	videoIds, err := clientInstance.ZRangeByScore(ctx, scheduleKey(clientKey), &redis.ZRangeBy{Min: "(" + now, Max: "+inf"}).Result()
+	if err != nil {
+		handleError(err, "Error when get youtube scheduled videos from redis", "error")
+		return nil, err
+	}
+
+	scheduledVideos := []*domain.YoutubeScheduledVideo{}
+	for _, videoId := range videoIds {
+		scheduledVideo, err := GetScheduledVideo(clientKey, videoId)
+		if errors.Is(err, ErrScheduleNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		scheduledVideos = append(scheduledVideos, scheduledVideo)
+	}
+	return scheduledVideos, nil
+}
+
+func DeleteScheduledVideo(clientKey string, videoId string) error {
+	_, err := clientInstance.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.ZRem(ctx, scheduleKey(clientKey), videoId)
+		pipe.HDel(ctx, scheduleInfoKey(clientKey), videoId)
+		return nil
+	})
+	if err != nil {
+		handleError(err, "Error when delete youtube scheduled video from redis", "error")
+		return err
+	}
+	return nil
+}
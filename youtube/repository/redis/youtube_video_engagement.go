@@ -2,17 +2,33 @@ package redis
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"tiktok_api/domain"
 	"time"
 
+	"github.com/redis/go-redis/v9"
 	"google.golang.org/api/youtube/v3"
 )
 
 const (
 	HSET_KEY = "youtube"
+	//- engagement fetched on demand stays in redis for this long, past its max age it is served stale while refreshed
+	ENGAGEMENT_EXPIRATION = 24 * time.Hour
+	//- set after a failed background refresh, stale reads skip youtube until it expires
+	ENGAGEMENT_BACKOFF_PREFIX = "youtube_engagement_backoff"
+	//- set when a poll did not find the video, kept long enough to be seen by the next poll
+	ENGAGEMENT_MISSING_PREFIX     = "youtube_engagement_missing"
+	ENGAGEMENT_MISSING_EXPIRATION = 7 * 24 * time.Hour
 )
 
+var ErrUploadInfoNotFound = errors.New("youtube upload info not found")
+
+// - engagement cache key, also the field of the upload info in the HSET_KEY hash
+func videoKey(clientKey string, videoId string) string {
+	return fmt.Sprintf("%s_%s", clientKey, videoId)
+}
+
 func SaveYoutubeFileUploadInfo(clientKey string, ytbFileUploadInfo *domain.YoutubeFileUploadInfo) (bool, error) {
 	byte, err := json.Marshal(&ytbFileUploadInfo)
 	if err != nil {
@@ -21,7 +37,7 @@ func SaveYoutubeFileUploadInfo(clientKey string, ytbFileUploadInfo *domain.Youtu
 		return false, err
 	}
 
-	err = clientInstance.HSet(ctx, HSET_KEY, clientKey, string(byte)).Err()
+	err = clientInstance.HSet(ctx, HSET_KEY, videoKey(clientKey, ytbFileUploadInfo.VideoId), string(byte)).Err()
 	if err != nil {
 		handleError(err, "Error when save youtube file upload info into redis", "error")
 		return false, err
@@ -29,9 +45,32 @@ func SaveYoutubeFileUploadInfo(clientKey string, ytbFileUploadInfo *domain.Youtu
 	return true, nil
 }
 
-func GetYoutubeFileUploadInfo(clientKey string, fileName string) (*domain.YoutubeFileUploadInfo, error) {
-	val, err := clientInstance.HGet(ctx, HSET_KEY, clientKey).Result()
+// - GetYoutubeFileUploadInfos returns every upload info of the HSET_KEY hash by field
+func GetYoutubeFileUploadInfos() (map[string]*domain.YoutubeFileUploadInfo, error) {
+	values, err := clientInstance.HGetAll(ctx, HSET_KEY).Result()
+	if err != nil {
+		handleError(err, "Error when get youtube file upload infos from redis", "error")
+		return nil, err
+	}
+
+	infos := make(map[string]*domain.YoutubeFileUploadInfo, len(values))
+	for field, val := range values {
+		youtubeFileUploadInfo := &domain.YoutubeFileUploadInfo{}
+		if err = json.Unmarshal([]byte(val), youtubeFileUploadInfo); err != nil {
+			handleError(err, fmt.Sprintf("Error when unmarshal youtube file upload info %s", field), "warn")
+			continue
+		}
+		infos[field] = youtubeFileUploadInfo
+	}
+	return infos, nil
+}
+
+func GetYoutubeFileUploadInfo(clientKey string, videoId string) (*domain.YoutubeFileUploadInfo, error) {
+	val, err := clientInstance.HGet(ctx, HSET_KEY, videoKey(clientKey, videoId)).Result()
 	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, ErrUploadInfoNotFound
+		}
 		handleError(err, "Error when get youtube file upload info from redis", "error")
 		return nil, err
 	}
@@ -45,8 +84,73 @@ func GetYoutubeFileUploadInfo(clientKey string, fileName string) (*domain.Youtub
 	return youtubeFileUploadInfo, nil
 }
 
-func SaveVideoEngagementInfo(clientKey string, videoId string, videoEngagement *youtube.VideoStatistics) (bool, error) {
-	videoClientKey := fmt.Sprintf("%s_%s", clientKey, videoId)
+// - MoveLegacyUploadInfo files an upload info saved under the bare client key, before the video id was part of the field,
+// - under the field of its video. An info already saved for the video wins over the legacy one.
+func MoveLegacyUploadInfo(clientKey string, ytbFileUploadInfo *domain.YoutubeFileUploadInfo) error {
+	byte, err := json.Marshal(&ytbFileUploadInfo)
+	if err != nil {
+		handleError(err, fmt.Sprintf("Error when json.Marshal into redis at key %s", clientKey), "error")
+		return err
+	}
+
+	_, err = clientInstance.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.HSetNX(ctx, HSET_KEY, videoKey(clientKey, ytbFileUploadInfo.VideoId), string(byte))
+		pipe.HDel(ctx, HSET_KEY, clientKey)
+		return nil
+	})
+	if err != nil {
+		handleError(err, "Error when move legacy youtube file upload info in redis", "error")
+	}
+	return err
+}
+
+func engagementBackoffKey(clientKey string, videoId string) string {
+	return fmt.Sprintf("%s_%s", ENGAGEMENT_BACKOFF_PREFIX, videoKey(clientKey, videoId))
+}
+
+// - BackOffEngagementRefresh holds the background refresh of the video for the given duration
+func BackOffEngagementRefresh(clientKey string, videoId string, backoff time.Duration) error {
+	err := clientInstance.Set(ctx, engagementBackoffKey(clientKey, videoId), 1, backoff).Err()
+	if err != nil {
+		handleError(err, fmt.Sprintf("Error when back off engagement refresh of video %s", videoId), "error")
+	}
+	return err
+}
+
+func EngagementRefreshBackedOff(clientKey string, videoId string) (bool, error) {
+	exists, err := clientInstance.Exists(ctx, engagementBackoffKey(clientKey, videoId)).Result()
+	if err != nil {
+		handleError(err, fmt.Sprintf("Error when read engagement refresh backoff of video %s", videoId), "error")
+		return false, err
+	}
+	return exists == 1, nil
+}
+
+func engagementMissingKey(clientKey string, videoId string) string {
+	return fmt.Sprintf("%s_%s", ENGAGEMENT_MISSING_PREFIX, videoKey(clientKey, videoId))
+}
+
+// - MarkVideoMissing records a poll which did not find the video, it tells whether the previous poll missed it too
+func MarkVideoMissing(clientKey string, videoId string) (bool, error) {
+	firstMiss, err := clientInstance.SetNX(ctx, engagementMissingKey(clientKey, videoId), 1, ENGAGEMENT_MISSING_EXPIRATION).Result()
+	if err != nil {
+		handleError(err, fmt.Sprintf("Error when mark youtube video %s missing", videoId), "error")
+		return false, err
+	}
+	return !firstMiss, nil
+}
+
+// - ClearVideoMissing forgets an earlier miss once a poll found the video again
+func ClearVideoMissing(clientKey string, videoId string) error {
+	err := clientInstance.Del(ctx, engagementMissingKey(clientKey, videoId)).Err()
+	if err != nil {
+		handleError(err, fmt.Sprintf("Error when clear missing youtube video %s", videoId), "error")
+	}
+	return err
+}
+
+func SaveVideoEngagementInfo(clientKey string, videoId string, videoEngagement *domain.YoutubeCachedEngagement, expiration time.Duration) (bool, error) {
+	videoClientKey := videoKey(clientKey, videoId)
 	byte, err := json.Marshal(&videoEngagement)
 	if err != nil {
 		//- writing logs and error handling
@@ -54,8 +158,7 @@ func SaveVideoEngagementInfo(clientKey string, videoId string, videoEngagement *
 		return false, err
 	}
 
-	expirationHour := 24 * time.Hour
-	err = clientInstance.Set(ctx, videoClientKey, string(byte), expirationHour).Err()
+	err = clientInstance.Set(ctx, videoClientKey, string(byte), expiration).Err()
 	if err != nil {
 		handleError(err, "Error when set TTL info into redis", "error")
 		return false, err
@@ -63,16 +166,19 @@ func SaveVideoEngagementInfo(clientKey string, videoId string, videoEngagement *
 	return true, nil
 }
 
-func GetVideoEngagementInfo(clientKey string, videoId string) (*youtube.VideoStatistics, error) {
-	videoClientKey := fmt.Sprintf("%s_%s", clientKey, videoId)
+// - GetVideoEngagementInfo returns nil when the engagement of the video is not cached
+func GetVideoEngagementInfo(clientKey string, videoId string) (*domain.YoutubeCachedEngagement, error) {
+	videoClientKey := videoKey(clientKey, videoId)
 	val, err := clientInstance.Get(ctx, videoClientKey).Result()
 	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, nil
+		}
 		handleError(err, "Error when get video engagement from redis", "error")
 		return nil, err
 	}
 
-	videoEngagementInfo := &youtube.VideoStatistics{}
-	err = json.Unmarshal([]byte(val), &videoEngagementInfo)
+	videoEngagementInfo, err := unmarshalVideoEngagement(val)
 	if err != nil {
 		handleError(err, "Error when unmarshal video engagement info from redis", "error")
 		return nil, err
@@ -80,36 +186,68 @@ func GetVideoEngagementInfo(clientKey string, videoId string) (*youtube.VideoSta
 	return videoEngagementInfo, nil
 }
 
-func IsYoutubeVideoEngagementExist(clientKey string, videoId string) (bool, bool, error) {
-	isExpireSoon := false
-	//- also check expiration?
-	//- redis hget check expiration
-	videoClientKey := fmt.Sprintf("%s_%s", clientKey, videoId)
+// - GetVideoEngagementInfos reads the cached engagement of many videos at once, videos not cached are left out
+func GetVideoEngagementInfos(clientKey string, videoIds []string) (map[string]*domain.YoutubeCachedEngagement, error) {
+	videoEngagements := map[string]*domain.YoutubeCachedEngagement{}
+	if len(videoIds) == 0 {
+		return videoEngagements, nil
+	}
 
-	remainingTime, err := clientInstance.TTL(ctx, videoClientKey).Result()
+	keys := make([]string, len(videoIds))
+	for i, videoId := range videoIds {
+		keys[i] = videoKey(clientKey, videoId)
+	}
+	values, err := clientInstance.MGet(ctx, keys...).Result()
 	if err != nil {
-		handleError(err, "Error when check TTL of a key from redis", "error")
-		return false, isExpireSoon, err
+		handleError(err, "Error when get video engagements from redis", "error")
+		return nil, err
 	}
 
-	isExist, err := clientInstance.Exists(ctx, videoClientKey).Result()
-	if err != nil {
-		handleError(err, "Error when get video engagement from redis", "error")
-		return false, isExpireSoon, err
+	for i, value := range values {
+		val, ok := value.(string)
+		if !ok {
+			continue
+		}
+		videoEngagementInfo, err := unmarshalVideoEngagement(val)
+		if err != nil {
+			handleError(err, "Error when unmarshal video engagement info from redis", "warn")
+			continue
+		}
+		videoEngagements[videoIds[i]] = videoEngagementInfo
 	}
-	fmt.Printf("isExist %v\n", isExist)
-	//- if not exist
-	if isExist == 0 {
-		return false, isExpireSoon, nil
+	return videoEngagements, nil
+}
+
+// - entries written before the fetch time was kept hold the bare statistics, they are returned without fetch time
+func unmarshalVideoEngagement(val string) (*domain.YoutubeCachedEngagement, error) {
+	videoEngagementInfo := &domain.YoutubeCachedEngagement{}
+	if err := json.Unmarshal([]byte(val), videoEngagementInfo); err != nil {
+		return nil, err
+	}
+	if videoEngagementInfo.Statistics != nil {
+		return videoEngagementInfo, nil
 	}
 
-	//- exist but expired soon
-	//- FIXME: Improve this logic
-	if remainingTime == 30*time.Second {
-		fmt.Printf("Remaining time %v\n", remainingTime)
-		isExpireSoon = true
+	statistics := &youtube.VideoStatistics{}
+	if err := json.Unmarshal([]byte(val), statistics); err != nil {
+		return nil, err
 	}
+	return &domain.YoutubeCachedEngagement{Statistics: statistics}, nil
+}
 
-	//- if exist
-	return true, isExpireSoon, nil
+// - DeleteVideoInfo forgets everything stored about a video deleted from youtube
+func DeleteVideoInfo(clientKey string, videoId string) error {
+	_, err := clientInstance.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Del(ctx, videoKey(clientKey, videoId), engagementHistoryKey(clientKey, videoId), engagementMissingKey(clientKey, videoId))
+		pipe.HDel(ctx, HSET_KEY, videoKey(clientKey, videoId))
+		pipe.ZRem(ctx, scheduleKey(clientKey), videoId)
+		pipe.HDel(ctx, scheduleInfoKey(clientKey), videoId)
+		pipe.ZRem(ctx, ENGAGEMENT_POLL_KEY, videoKey(clientKey, videoId))
+		return nil
+	})
+	if err != nil {
+		handleError(err, fmt.Sprintf("Error when delete youtube video %s from redis", videoId), "error")
+		return err
+	}
+	return nil
 }
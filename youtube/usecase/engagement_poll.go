@@ -0,0 +1,227 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"tiktok_api/app/utils"
+	"tiktok_api/domain"
+	"tiktok_api/youtube/repository/redis"
+	"time"
+
+	"google.golang.org/api/youtube/v3"
+)
+
+const (
+	//- videos polled in one run, the rest stay due and are picked up by the next run
+	engagementPollBatchSize = 500
+	//- the cached engagement outlives the poll interval so readers never fall back to youtube between two polls
+	engagementPollExpirationMargin = time.Hour
+)
+
+// - DefaultEngagementPollSettings polls every hour during the first 48 hours after the upload and then once a day
+func DefaultEngagementPollSettings() *domain.YoutubeEngagementPollSettings {
+	return &domain.YoutubeEngagementPollSettings{
+		EarlyInterval: domain.Duration(utils.DurationOrDefault("YOUTUBE.ENGAGEMENT_POLL_EARLY_INTERVAL", time.Hour)),
+		EarlyPeriod:   domain.Duration(utils.DurationOrDefault("YOUTUBE.ENGAGEMENT_POLL_EARLY_PERIOD", 48*time.Hour)),
+		LateInterval:  domain.Duration(utils.DurationOrDefault("YOUTUBE.ENGAGEMENT_POLL_LATE_INTERVAL", 24*time.Hour)),
+	}
+}
+
+// - GetEngagementPollSettings returns the polling settings of the client key, the defaults when none were saved
+func GetEngagementPollSettings(clientKey string) (*domain.YoutubeEngagementPollSettings, error) {
+	if _, err := getClient(clientKey); err != nil {
+		return nil, err
+	}
+	return engagementPollSettings(clientKey)
+}
+
+func UpdateEngagementPollSettings(clientKey string, settings *domain.YoutubeEngagementPollSettings) error {
+	if _, err := getClient(clientKey); err != nil {
+		return err
+	}
+	if err := settings.Validate(); err != nil {
+		return err
+	}
+
+	_, err := redis.SaveEngagementPollSettings(clientKey, settings)
+	return err
+}
+
+func engagementPollSettings(clientKey string) (*domain.YoutubeEngagementPollSettings, error) {
+	settings, err := redis.GetEngagementPollSettings(clientKey)
+	if err != nil {
+		return nil, err
+	}
+	if settings == nil {
+		return DefaultEngagementPollSettings(), nil
+	}
+	return settings, nil
+}
+
+// - scheduleEngagementPoll queues the first poll of a freshly uploaded video, a failure only delays it to the backfill
+func scheduleEngagementPoll(clientKey string, videoId string, uploadedAt time.Time) {
+	settings, err := engagementPollSettings(clientKey)
+	if err != nil {
+		handleError(err, fmt.Sprintf("Unable to schedule engagement poll of video %s", videoId), "warn")
+		return
+	}
+	if uploadedAt.IsZero() {
+		uploadedAt = time.Now()
+	}
+	if err = redis.ScheduleEngagementPoll(clientKey, videoId, settings.NextPoll(uploadedAt, uploadedAt)); err != nil {
+		handleError(err, fmt.Sprintf("Unable to schedule engagement poll of video %s", videoId), "warn")
+	}
+}
+
+// - BackfillEngagementPolls queues every uploaded video of a connected account which is not polled yet, they are due right away.
+// - Videos found deleted by the poller lose their upload info and are not queued again.
+func BackfillEngagementPolls() error {
+	infos, err := redis.GetYoutubeFileUploadInfos()
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	connected := map[string]bool{}
+	for field, info := range infos {
+		clientKey, _, ok := strings.Cut(field, "_")
+		if !ok {
+			//- saved under the bare client key before the video id was part of the field
+			clientKey = field
+		}
+		if _, checked := connected[clientKey]; !checked {
+			connected[clientKey] = redis.GetClientByClientKey(clientKey) != nil
+		}
+		if !connected[clientKey] {
+			//- the account is gone, its videos are not polled anymore
+			continue
+		}
+		if !ok {
+			//- legacy infos without their video id cannot be matched to a video, they are left alone
+			if info.VideoId == "" {
+				continue
+			}
+			if err = redis.MoveLegacyUploadInfo(clientKey, info); err != nil {
+				handleError(err, fmt.Sprintf("Unable to move the upload info of client key %s", clientKey), "error")
+				continue
+			}
+		}
+		if info.VideoId == "" {
+			continue
+		}
+		if err = redis.AddEngagementPoll(clientKey, info.VideoId, now); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// - PollDueEngagements refreshes the cached engagement of every video whose poll is due.
+// - Errors of a single client key are logged and retried later so one account never blocks the others.
+func PollDueEngagements() error {
+	now := time.Now()
+	due, err := redis.GetDueEngagementPolls(now, engagementPollBatchSize)
+	if err != nil {
+		return err
+	}
+
+	for clientKey, videoIds := range due {
+		if err = pollClientEngagements(clientKey, videoIds, now); err != nil {
+			handleError(err, fmt.Sprintf("Unable to poll engagement of client key %s", clientKey), "error")
+		}
+	}
+	return nil
+}
+
+func pollClientEngagements(clientKey string, videoIds []string, now time.Time) error {
+	youtubeOAuth := redis.GetClientByClientKey(clientKey)
+	if youtubeOAuth == nil {
+		//- account is gone, nothing left to poll
+		for _, videoId := range videoIds {
+			if err := redis.RemoveEngagementPoll(clientKey, videoId); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	settings, err := engagementPollSettings(clientKey)
+	if err != nil {
+		return err
+	}
+
+	//- polling stays paused until the account is reconnected or polling is enabled again
+	if youtubeOAuth.ReconnectRequired || settings.Disabled {
+		for _, videoId := range videoIds {
+			if err = redis.ScheduleEngagementPoll(clientKey, videoId, now.Add(time.Duration(settings.LateInterval))); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	service, err := BuildServiceFromToken(clientKey)
+	if err != nil {
+		return err
+	}
+	for start := 0; start < len(videoIds); start += domain.YoutubeVideosListMaxIds {
+		end := start + domain.YoutubeVideosListMaxIds
+		if end > len(videoIds) {
+			end = len(videoIds)
+		}
+		batch := videoIds[start:end]
+
+		statistics, err := listVideoStatistics(service, batch)
+		if err != nil {
+			//- retried after the short interval, the quota or the network is usually back by then
+			for _, videoId := range batch {
+				if err = redis.ScheduleEngagementPoll(clientKey, videoId, now.Add(time.Duration(settings.EarlyInterval))); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+
+		for _, videoId := range batch {
+			if err = pollVideoEngagement(clientKey, videoId, statistics[videoId], settings, now); err != nil {
+				handleError(err, fmt.Sprintf("Unable to save engagement of video %s", videoId), "error")
+			}
+		}
+	}
+	return nil
+}
+
+func pollVideoEngagement(clientKey string, videoId string, statistics *youtube.VideoStatistics, settings *domain.YoutubeEngagementPollSettings, now time.Time) error {
+	if statistics == nil {
+		//- videos.list can leave a video out for a while, only a second miss in a row means it was deleted on youtube
+		//- or is no longer owned by the account. Dropping the upload info then keeps the backfill from queueing it again.
+		missedBefore, err := redis.MarkVideoMissing(clientKey, videoId)
+		if err != nil {
+			return err
+		}
+		if missedBefore {
+			return redis.DeleteVideoInfo(clientKey, videoId)
+		}
+		return redis.ScheduleEngagementPoll(clientKey, videoId, now.Add(time.Duration(settings.EarlyInterval)))
+	}
+	if err := redis.ClearVideoMissing(clientKey, videoId); err != nil {
+		return err
+	}
+
+	//- videos without upload info are treated as past their early period
+	uploadedAt := time.Time{}
+	info, err := redis.GetYoutubeFileUploadInfo(clientKey, videoId)
+	if err != nil && !errors.Is(err, redis.ErrUploadInfoNotFound) {
+		return err
+	}
+	if info != nil {
+		uploadedAt = info.CreatedAt
+	}
+
+	interval := settings.Interval(uploadedAt, now)
+	if _, err = cacheVideoEngagement(clientKey, videoId, statistics, interval, interval+engagementPollExpirationMargin); err != nil {
+		return err
+	}
+	return redis.ScheduleEngagementPoll(clientKey, videoId, now.Add(interval))
+}
@@ -11,6 +11,7 @@ import (
 	"os/user"
 	"path/filepath"
 	"tiktok_api/app/logger"
+	"tiktok_api/domain"
 	"tiktok_api/youtube/repository/redis"
 
 	"golang.org/x/oauth2"
@@ -22,6 +23,9 @@ var log = logger.NewLogrusLogger()
 var ctx = context.Background()
 var config *oauth2.Config
 
+var ErrClientKeyNotFound = errors.New("youtube client key not found")
+var ErrReconnectRequired = errors.New("youtube account has to be reconnected, its refresh token is no longer valid")
+
 func init() {
 	path, err := os.Getwd()
 	if err != nil {
@@ -70,6 +74,7 @@ func YoutubeOAuthCodeExchange(clientKey string, code string) string {
 	youtubeOAuth.AccessToken = tokens.AccessToken
 	youtubeOAuth.RefreshToken = tokens.RefreshToken
 	youtubeOAuth.Expiry = tokens.Expiry
+	youtubeOAuth.ReconnectRequired = false
 
 	isUpdate := redis.UpdateYoutubeByClientKey(clientKey, youtubeOAuth)
 	if !isUpdate {
@@ -80,37 +85,47 @@ func YoutubeOAuthCodeExchange(clientKey string, code string) string {
 	return successURL
 }
 
-func BuildClientFromTokens(clientKey string) *http.Client {
-	//- FIXME: check if clientKey is exist
-
-	//- get client tokens base on clientKey
+// - getClient returns the youtube record of the client key
+func getClient(clientKey string) (*domain.YoutubeOAuth, error) {
 	youtubeOAuth := redis.GetClientByClientKey(clientKey)
-	tokens := &oauth2.Token{
-		AccessToken:  youtubeOAuth.AccessToken,
-		RefreshToken: youtubeOAuth.RefreshToken,
-		Expiry:       youtubeOAuth.Expiry,
-		TokenType:    "Bearer",
+	if youtubeOAuth == nil {
+		return nil, ErrClientKeyNotFound
 	}
-	return config.Client(ctx, tokens)
+	return youtubeOAuth, nil
 }
 
-func BuildServiceFromToken(clientKey string) *youtube.Service {
-	//- FIXME: check if clientKey is exist
+// - getConnectedClient also refuses accounts whose refresh token was revoked, youtube cannot be called for them
+func getConnectedClient(clientKey string) (*domain.YoutubeOAuth, error) {
+	youtubeOAuth, err := getClient(clientKey)
+	if err != nil {
+		return nil, err
+	}
+	if youtubeOAuth.ReconnectRequired {
+		return nil, ErrReconnectRequired
+	}
+	return youtubeOAuth, nil
+}
 
+func BuildClientFromTokens(clientKey string) (*http.Client, error) {
 	//- get client tokens base on clientKey
-	youtubeOAuth := redis.GetClientByClientKey(clientKey)
-	tokens := &oauth2.Token{
-		AccessToken:  youtubeOAuth.AccessToken,
-		RefreshToken: youtubeOAuth.RefreshToken,
-		Expiry:       youtubeOAuth.Expiry,
-		TokenType:    "Bearer",
+	youtubeOAuth, err := getConnectedClient(clientKey)
+	if err != nil {
+		return nil, err
+	}
+	return withQuota(oauth2.NewClient(ctx, newTokenSource(clientKey, youtubeOAuth))), nil
+}
+
+func BuildServiceFromToken(clientKey string) (*youtube.Service, error) {
+	client, err := BuildClientFromTokens(clientKey)
+	if err != nil {
+		return nil, err
 	}
-	client := config.Client(ctx, tokens)
 	service, err := youtube.New(client)
 	if err != nil {
 		handleError(err, "Unable to create Youtube service", "error")
+		return nil, err
 	}
-	return service
+	return service, nil
 }
 
 func GetAuthURL() (string, string) {
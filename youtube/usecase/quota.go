@@ -0,0 +1,118 @@
+package usecase
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"tiktok_api/domain"
+	"tiktok_api/youtube/repository/redis"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+var ErrQuotaExceeded = errors.New("youtube quota exceeded")
+
+// - quota is shared by every account connected through the same google cloud project
+func quotaProject() string {
+	if project := viper.GetString("YOUTUBE.QUOTA_PROJECT"); project != "" {
+		return project
+	}
+	return config.ClientID
+}
+
+func quotaBudget() int64 {
+	if budget := viper.GetInt64("YOUTUBE.QUOTA_DAILY_BUDGET"); budget > 0 {
+		return budget
+	}
+	return domain.YoutubeQuotaDefaultBudget
+}
+
+// - YoutubeQuotaUsage returns the quota used today against the configured budget
+func YoutubeQuotaUsage() (*domain.YoutubeQuotaUsage, error) {
+	now := time.Now()
+	used, err := redis.GetQuotaUsage(quotaProject(), domain.YoutubeQuotaDay(now))
+	if err != nil {
+		return nil, err
+	}
+	return domain.NewYoutubeQuotaUsage(quotaProject(), used, quotaBudget(), now), nil
+}
+
+// - CheckQuota fails when the budget left today cannot cover cost, nothing is counted
+func CheckQuota(cost int64) error {
+	usage, err := YoutubeQuotaUsage()
+	if err != nil {
+		return err
+	}
+	if usage.Remaining < cost {
+		return quotaExceededError(usage, cost)
+	}
+	return nil
+}
+
+// - reserveQuota counts cost units before a call is sent, quota accounting being down never blocks the call
+func reserveQuota(cost int64) error {
+	now := time.Now()
+	project := quotaProject()
+	budget := quotaBudget()
+	reserved, used, err := redis.ReserveQuota(project, domain.YoutubeQuotaDay(now), cost, budget, domain.YoutubeQuotaResetAt(now))
+	if err != nil {
+		return nil
+	}
+	if !reserved {
+		return quotaExceededError(domain.NewYoutubeQuotaUsage(project, used, budget, now), cost)
+	}
+	return nil
+}
+
+func quotaExceededError(usage *domain.YoutubeQuotaUsage, cost int64) error {
+	return fmt.Errorf("%w: %d of %d units used today, %d needed, resets at %s",
+		ErrQuotaExceeded, usage.Used, usage.Budget, cost, usage.ResetAt.Format(time.RFC3339))
+}
+
+// - quotaTransport counts the cost of every youtube call against the daily budget and refuses calls going over it.
+// - When youtube itself answers quotaExceeded the budget of the day is marked as used up.
+type quotaTransport struct {
+	base http.RoundTripper
+}
+
+func (t *quotaTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if cost := domain.YoutubeQuotaCost(req.Method, req.URL); cost > 0 {
+		if err := reserveQuota(cost); err != nil {
+			if req.Body != nil {
+				req.Body.Close()
+			}
+			return nil, err
+		}
+	}
+
+	response, err := t.base.RoundTrip(req)
+	if err == nil && response.StatusCode == http.StatusForbidden && quotaExceededResponse(response) {
+		now := time.Now()
+		redis.ExhaustQuota(quotaProject(), domain.YoutubeQuotaDay(now), quotaBudget(), domain.YoutubeQuotaResetAt(now))
+	}
+	return response, err
+}
+
+// - quotaExceededResponse reads the error reason of a 403, the body is put back for the api client
+func quotaExceededResponse(response *http.Response) bool {
+	body, err := io.ReadAll(response.Body)
+	response.Body.Close()
+	response.Body = io.NopCloser(bytes.NewReader(body))
+	if err != nil {
+		return false
+	}
+	return bytes.Contains(body, []byte(`"quotaExceeded"`)) || bytes.Contains(body, []byte(`"dailyLimitExceeded"`))
+}
+
+// - withQuota wraps the transport of a youtube http client with the quota accounting
+func withQuota(client *http.Client) *http.Client {
+	base := client.Transport
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	client.Transport = &quotaTransport{base: base}
+	return client
+}
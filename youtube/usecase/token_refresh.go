@@ -0,0 +1,74 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"tiktok_api/domain"
+	"tiktok_api/youtube/repository/redis"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+// - RefreshExpiringTokens refreshes every youtube access token expiring within the given window.
+// - Accounts whose refresh token is rejected by google are marked as reconnect required.
+func RefreshExpiringTokens(within time.Duration) error {
+	clientKeys, err := redis.GetClientKeysExpiringBefore(time.Now().Add(within))
+	if err != nil {
+		return err
+	}
+
+	for _, clientKey := range clientKeys {
+		if err = refreshClientToken(clientKey); err != nil {
+			handleError(err, fmt.Sprintf("Unable to refresh token of client key %s", clientKey), "error")
+		}
+	}
+	return nil
+}
+
+// - IndexExistingTokens adds the accounts connected before the refresher index existed, run once before the first refresh
+func IndexExistingTokens() error {
+	return redis.IndexAllTokenExpiries()
+}
+
+func refreshClientToken(clientKey string) error {
+	youtubeOAuth := redis.GetClientByClientKey(clientKey)
+	if youtubeOAuth == nil {
+		//- record is gone, nothing left to refresh
+		return redis.RemoveTokenExpiry(clientKey)
+	}
+
+	//- a token without access token is always refreshed by the token source
+	token, err := config.TokenSource(ctx, &oauth2.Token{RefreshToken: youtubeOAuth.RefreshToken}).Token()
+	if err != nil {
+		//- network errors, google 5xx, rate limits and client misconfiguration are retried on the next run
+		var retrieveErr *oauth2.RetrieveError
+		if errors.As(err, &retrieveErr) && grantRevoked(retrieveErr) {
+			return markReconnectRequired(clientKey, youtubeOAuth, err)
+		}
+		return err
+	}
+
+	youtubeOAuth.AccessToken = token.AccessToken
+	youtubeOAuth.RefreshToken = token.RefreshToken
+	youtubeOAuth.Expiry = token.Expiry
+	if !redis.UpdateYoutubeByClientKey(clientKey, youtubeOAuth) {
+		return errors.New("Update youtube token failed")
+	}
+	return nil
+}
+
+// - grantRevoked tells whether google refused the refresh token itself, only then reconnecting is needed.
+// - Other refusals like invalid_client come from the configuration and are shared by every account, they are retried.
+func grantRevoked(retrieveErr *oauth2.RetrieveError) bool {
+	return retrieveErr.ErrorCode == "invalid_grant"
+}
+
+func markReconnectRequired(clientKey string, youtubeOAuth *domain.YoutubeOAuth, cause error) error {
+	youtubeOAuth.ReconnectRequired = true
+	if !redis.UpdateYoutubeByClientKey(clientKey, youtubeOAuth) {
+		return errors.New("Update youtube token failed")
+	}
+	handleError(cause, fmt.Sprintf("Client key %s has to reconnect its youtube account", clientKey), "warn")
+	return nil
+}
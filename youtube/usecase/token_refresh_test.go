@@ -0,0 +1,27 @@
+package usecase
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/oauth2"
+)
+
+func TestGrantRevoked(t *testing.T) {
+	cases := []struct {
+		name      string
+		errorCode string
+		expected  bool
+	}{
+		{"revoked refresh token", "invalid_grant", true},
+		{"misconfigured client", "invalid_client", false},
+		{"client not allowed", "unauthorized_client", false},
+		{"server error without code", "", false},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			assert.Equal(t, c.expected, grantRevoked(&oauth2.RetrieveError{ErrorCode: c.errorCode}))
+		})
+	}
+}
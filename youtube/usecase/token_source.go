@@ -0,0 +1,62 @@
+package usecase
+
+import (
+	"sync"
+	"tiktok_api/domain"
+	"tiktok_api/youtube/repository/redis"
+
+	"golang.org/x/oauth2"
+)
+
+// - persistingTokenSource writes every refreshed token back to the youtube record of the client key,
+// - so the next request reuses it instead of refreshing again and a rotated refresh token is never lost
+type persistingTokenSource struct {
+	clientKey   string
+	base        oauth2.TokenSource
+	mu          sync.Mutex
+	accessToken string
+}
+
+func (s *persistingTokenSource) Token() (*oauth2.Token, error) {
+	token, err := s.base.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if token.AccessToken == s.accessToken {
+		return token, nil
+	}
+	s.accessToken = token.AccessToken
+
+	//- re-read the record so fields changed since the client was built are not overwritten
+	youtubeOAuth := redis.GetClientByClientKey(s.clientKey)
+	if youtubeOAuth == nil {
+		return token, nil
+	}
+	youtubeOAuth.AccessToken = token.AccessToken
+	youtubeOAuth.RefreshToken = token.RefreshToken
+	youtubeOAuth.Expiry = token.Expiry
+	youtubeOAuth.ReconnectRequired = false
+	if !redis.UpdateYoutubeByClientKey(s.clientKey, youtubeOAuth) {
+		//- the refreshed token is still usable for this request
+		handleError(nil, "Error when persist refreshed youtube token", "warn")
+	}
+	return token, nil
+}
+
+// - newTokenSource returns a token source for the stored tokens that refreshes them when expired and persists the result
+func newTokenSource(clientKey string, youtubeOAuth *domain.YoutubeOAuth) oauth2.TokenSource {
+	tokens := &oauth2.Token{
+		AccessToken:  youtubeOAuth.AccessToken,
+		RefreshToken: youtubeOAuth.RefreshToken,
+		Expiry:       youtubeOAuth.Expiry,
+		TokenType:    "Bearer",
+	}
+	return oauth2.ReuseTokenSource(tokens, &persistingTokenSource{
+		clientKey:   clientKey,
+		base:        config.TokenSource(ctx, tokens),
+		accessToken: tokens.AccessToken,
+	})
+}
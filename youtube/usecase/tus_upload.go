@@ -0,0 +1,294 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"tiktok_api/app/utils"
+	"tiktok_api/domain"
+	"tiktok_api/youtube/repository/redis"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+var ErrTusOffsetMismatch = errors.New("Upload-Offset does not match the offset of the upload")
+var ErrTusUploadLocked = errors.New("upload is being written by another request")
+var ErrTusUploadCompleted = errors.New("upload is complete and has been handed to its upload job")
+
+// - tusLock is held by one request at a time, it is extended in the background until released
+// - so a long chunk never outlives it while a crashed request frees it quickly
+type tusLock struct {
+	uploadId string
+	owner    string
+	//- set once the lock could not be extended, another request may hold it by now
+	lost atomic.Bool
+	stop chan struct{}
+	done chan struct{}
+}
+
+func lockTusUpload(uploadId string) (*tusLock, error) {
+	owner, err := redis.LockTusUpload(uploadId)
+	if err != nil {
+		return nil, err
+	}
+	if owner == "" {
+		return nil, ErrTusUploadLocked
+	}
+
+	lock := &tusLock{uploadId: uploadId, owner: owner, stop: make(chan struct{}), done: make(chan struct{})}
+	go lock.keepAlive()
+	return lock, nil
+}
+
+func (l *tusLock) keepAlive() {
+	defer close(l.done)
+	ticker := time.NewTicker(redis.TUS_LOCK_EXPIRATION / 3)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-l.stop:
+			return
+		case <-ticker.C:
+			extended, err := redis.ExtendTusUploadLock(l.uploadId, l.owner)
+			if err != nil {
+				//- tried again on the next tick, the lock is still valid for a while
+				continue
+			}
+			if !extended {
+				l.lost.Store(true)
+				return
+			}
+		}
+	}
+}
+
+func (l *tusLock) release() {
+	close(l.stop)
+	<-l.done
+	redis.UnlockTusUpload(l.uploadId, l.owner)
+}
+
+// - tusLockedReader stops a chunk as soon as its request lost the lock of the upload
+type tusLockedReader struct {
+	reader io.Reader
+	lock   *tusLock
+}
+
+func (r *tusLockedReader) Read(p []byte) (int, error) {
+	if r.lock.lost.Load() {
+		return 0, ErrTusUploadLocked
+	}
+	return r.reader.Read(p)
+}
+
+// - tusUploadExpiration is how long an unfinished upload waits for its next chunk before it is reaped
+func tusUploadExpiration() time.Duration {
+	return utils.DurationOrDefault("YOUTUBE.TUS_UPLOAD_EXPIRATION", 24*time.Hour)
+}
+
+func tusUploadDir() string {
+	if dir := viper.GetString("YOUTUBE.TUS_UPLOAD_DIR"); dir != "" {
+		return dir
+	}
+	return filepath.Join(os.TempDir(), "tus-uploads")
+}
+
+// - CreateTusUpload reserves an empty file on disk for an upload of length bytes.
+// - The upload id is also the id of the upload job created once every byte has arrived.
+func CreateTusUpload(clientKey string, length int64, rawMetadata map[string]string, metadata *domain.YoutubeVideoMetadata, ytbFileUploadInfo *domain.YoutubeFileUploadInfo) (*domain.TusUpload, error) {
+	metadata.SetDefaults()
+	if err := metadata.Validate(); err != nil {
+		return nil, err
+	}
+	if _, err := getConnectedClient(clientKey); err != nil {
+		return nil, err
+	}
+	//- refused before the client sends gigabytes youtube would not accept today
+	if err := CheckQuota(domain.YoutubeQuotaVideoInsertCost); err != nil {
+		return nil, err
+	}
+
+	dir := tusUploadDir()
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		handleError(err, "Error when create tus upload directory", "error")
+		return nil, err
+	}
+
+	uploadId, err := redis.NewTusUploadId()
+	if err != nil {
+		return nil, err
+	}
+	filePath := filepath.Join(dir, uploadId)
+	file, err := os.OpenFile(filePath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+	if err != nil {
+		handleError(err, "Error when create tus upload file", "error")
+		return nil, err
+	}
+	file.Close()
+
+	upload := &domain.TusUpload{
+		Id:            uploadId,
+		ClientKey:     clientKey,
+		Length:        length,
+		Metadata:      rawMetadata,
+		FilePath:      filePath,
+		FileInfo:      ytbFileUploadInfo,
+		VideoMetadata: metadata,
+		ExpiresAt:     time.Now().Add(tusUploadExpiration()),
+		CreatedAt:     time.Now(),
+	}
+	if _, err = redis.SaveTusUpload(upload); err != nil {
+		os.Remove(filePath)
+		return nil, err
+	}
+	return upload, nil
+}
+
+// - GetTusUpload answers an expired upload as not found, the reaper removes it a bit later
+func GetTusUpload(uploadId string) (*domain.TusUpload, error) {
+	upload, err := redis.GetTusUpload(uploadId)
+	if err != nil {
+		return nil, err
+	}
+	if upload.Expired(time.Now()) {
+		return nil, redis.ErrTusUploadNotFound
+	}
+	return upload, nil
+}
+
+// - WriteTusChunk writes the chunk at offset. Bytes received before an interrupted request are kept,
+// - so the client resumes from the offset returned by HEAD. The last chunk queues the upload job.
+func WriteTusChunk(uploadId string, offset int64, chunk io.Reader) (*domain.TusUpload, error) {
+	lock, err := lockTusUpload(uploadId)
+	if err != nil {
+		return nil, err
+	}
+	defer lock.release()
+
+	upload, err := GetTusUpload(uploadId)
+	if err != nil {
+		return nil, err
+	}
+	if upload.Offset != offset {
+		return upload, ErrTusOffsetMismatch
+	}
+	if upload.Completed() {
+		return upload, queueTusUploadJob(upload)
+	}
+
+	file, err := os.OpenFile(upload.FilePath, os.O_WRONLY, 0600)
+	if err != nil {
+		handleError(err, "Error when open tus upload file", "error")
+		return nil, err
+	}
+	if _, err = file.Seek(offset, io.SeekStart); err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	//- bytes past the declared length are never read
+	written, copyErr := io.Copy(file, &tusLockedReader{reader: io.LimitReader(chunk, upload.Length-upload.Offset), lock: lock})
+	if err = file.Close(); err != nil && copyErr == nil {
+		copyErr = err
+	}
+	if lock.lost.Load() {
+		//- another request may have saved its own offset meanwhile
+		return nil, ErrTusUploadLocked
+	}
+
+	upload.Offset += written
+	upload.ExpiresAt = time.Now().Add(tusUploadExpiration())
+	if _, err = redis.SaveTusUpload(upload); err != nil {
+		return nil, err
+	}
+	if copyErr != nil {
+		return upload, copyErr
+	}
+
+	if upload.Completed() {
+		return upload, queueTusUploadJob(upload)
+	}
+	return upload, nil
+}
+
+// - queueTusUploadJob is safe to repeat, a client retrying the last PATCH after a failure queues the job only once
+func queueTusUploadJob(upload *domain.TusUpload) error {
+	_, err := redis.GetUploadJob(upload.Id)
+	if !errors.Is(err, redis.ErrJobNotFound) {
+		return err
+	}
+	_, err = createUploadJob(upload.Id, upload.ClientKey, upload.FilePath, "", upload.VideoMetadata, upload.FileInfo)
+	return err
+}
+
+// - TerminateTusUpload drops an unfinished upload and its bytes on disk
+func TerminateTusUpload(uploadId string) error {
+	lock, err := lockTusUpload(uploadId)
+	if err != nil {
+		return err
+	}
+	defer lock.release()
+
+	upload, err := redis.GetTusUpload(uploadId)
+	if err != nil {
+		return err
+	}
+	if upload.Completed() {
+		return ErrTusUploadCompleted
+	}
+	return removeTusUpload(uploadId, upload.FilePath)
+}
+
+func removeTusUpload(uploadId string, filePath string) error {
+	if err := os.Remove(filePath); err != nil && !errors.Is(err, os.ErrNotExist) {
+		handleError(err, "Error when remove tus upload file", "error")
+		return err
+	}
+	return redis.DeleteTusUpload(uploadId)
+}
+
+// - ReapExpiredTusUploads removes the unfinished uploads whose client stopped sending chunks, with their bytes on disk.
+// - Uploads being written are skipped, the chunk pushes their expiration back.
+func ReapExpiredTusUploads() error {
+	uploadIds, err := redis.GetExpiredTusUploadIds(time.Now())
+	if err != nil {
+		return err
+	}
+
+	for _, uploadId := range uploadIds {
+		if err = reapTusUpload(uploadId); err != nil {
+			handleError(err, fmt.Sprintf("Unable to reap tus upload %s", uploadId), "error")
+		}
+	}
+	return nil
+}
+
+func reapTusUpload(uploadId string) error {
+	lock, err := lockTusUpload(uploadId)
+	if errors.Is(err, ErrTusUploadLocked) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer lock.release()
+
+	//- the record outlives the expiration by a grace period, past it the file is still where the upload put it
+	filePath := filepath.Join(tusUploadDir(), uploadId)
+	upload, err := redis.GetTusUpload(uploadId)
+	if err != nil && !errors.Is(err, redis.ErrTusUploadNotFound) {
+		return err
+	}
+	if upload != nil {
+		if !upload.Expired(time.Now()) {
+			//- a chunk arrived since the ids were read
+			return nil
+		}
+		filePath = upload.FilePath
+	}
+	return removeTusUpload(uploadId, filePath)
+}
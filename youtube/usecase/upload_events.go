@@ -0,0 +1,62 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"tiktok_api/domain"
+	"tiktok_api/youtube/repository/redis"
+)
+
+// - WatchUploadJob sends the current state of the job followed by every change until the job finishes or ctx is done.
+// - The channel is closed at the end, the last job sent is the finished one unless ctx was cancelled first.
+func WatchUploadJob(watchCtx context.Context, jobId string) (<-chan *domain.UploadJob, error) {
+	//- subscribe before reading the current state so no change falls in between
+	pubsub, err := redis.SubscribeUploadJob(jobId)
+	if err != nil {
+		return nil, err
+	}
+	job, err := redis.GetUploadJob(jobId)
+	if err != nil {
+		pubsub.Close()
+		return nil, err
+	}
+
+	jobs := make(chan *domain.UploadJob)
+	go func() {
+		defer close(jobs)
+		defer pubsub.Close()
+
+		send := func(job *domain.UploadJob) bool {
+			select {
+			case jobs <- job:
+				return !job.Finished()
+			case <-watchCtx.Done():
+				return false
+			}
+		}
+
+		if !send(job) {
+			return
+		}
+		messages := pubsub.Channel()
+		for {
+			select {
+			case <-watchCtx.Done():
+				return
+			case message, ok := <-messages:
+				if !ok {
+					return
+				}
+				job := &domain.UploadJob{}
+				if err := json.Unmarshal([]byte(message.Payload), job); err != nil {
+					handleError(err, "Error when unmarshal upload job event", "error")
+					continue
+				}
+				if !send(job) {
+					return
+				}
+			}
+		}
+	}()
+	return jobs, nil
+}
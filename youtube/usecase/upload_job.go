@@ -0,0 +1,327 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"tiktok_api/domain"
+	"tiktok_api/youtube/repository/redis"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+const (
+	defaultUploadWorkers            = 2
+	defaultProcessingPollInterval   = 15 * time.Second
+	defaultProcessingPollAttempts   = 20
+	uploadJobDequeueTimeout         = 5 * time.Second
+	uploadJobRetryAfterQueueFailure = 5 * time.Second
+)
+
+// - CreateUploadJob queues the video waiting on disk at filePath, the files are removed once the job finishes.
+// - The publish time is checked here only, with the youtube quota spent the job is deferred until the quota is reset.
+// - thumbnailPath is optional and points at an image already checked by DetectThumbnailType.
+func CreateUploadJob(clientKey string, filePath string, thumbnailPath string, metadata *domain.YoutubeVideoMetadata, ytbFileUploadInfo *domain.YoutubeFileUploadInfo) (*domain.UploadJob, error) {
+	metadata.SetDefaults()
+	if err := metadata.Validate(); err != nil {
+		return nil, err
+	}
+	if _, err := getConnectedClient(clientKey); err != nil {
+		return nil, err
+	}
+	jobId, err := redis.NewJobId()
+	if err != nil {
+		return nil, err
+	}
+	return createUploadJob(jobId, clientKey, filePath, thumbnailPath, metadata, ytbFileUploadInfo)
+}
+
+func createUploadJob(jobId string, clientKey string, filePath string, thumbnailPath string, metadata *domain.YoutubeVideoMetadata, ytbFileUploadInfo *domain.YoutubeFileUploadInfo) (*domain.UploadJob, error) {
+	job := &domain.UploadJob{
+		Id:            jobId,
+		ClientKey:     clientKey,
+		Status:        domain.UploadJobQueued,
+		FilePath:      filePath,
+		ThumbnailPath: thumbnailPath,
+		FileInfo:      ytbFileUploadInfo,
+		Metadata:      metadata,
+		BytesTotal:    ytbFileUploadInfo.FileSize,
+		CreatedAt:     time.Now(),
+	}
+	if _, err := redis.SaveUploadJob(job); err != nil {
+		return nil, err
+	}
+
+	//- the video is on disk already, it waits for the quota reset instead of being sent again by the client
+	if err := CheckQuota(domain.YoutubeQuotaVideoInsertCost); errors.Is(err, ErrQuotaExceeded) {
+		deferUploadJob(job, err)
+		if job.Finished() {
+			//- the job could not be deferred and failed
+			return nil, errors.New(job.Error)
+		}
+		return job, nil
+	}
+	if err := redis.EnqueueUploadJob(job.Id); err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+func GetUploadJob(jobId string) (*domain.UploadJob, error) {
+	return redis.GetUploadJob(jobId)
+}
+
+// - StartUploadWorkers starts the worker pool, every worker keeps a heartbeat in redis while the app runs
+// - so the jobs it holds are only requeued once it is gone
+func StartUploadWorkers(ctx context.Context, workers int) {
+	if workers <= 0 {
+		workers = defaultUploadWorkers
+	}
+
+	for i := 0; i < workers; i++ {
+		workerId, err := redis.NewUploadWorkerId()
+		if err != nil {
+			continue
+		}
+		go uploadWorker(ctx, workerId)
+	}
+}
+
+// - RequeueInterruptedUploadJobs queues again the jobs held by workers of an instance that stopped or crashed
+func RequeueInterruptedUploadJobs() error {
+	jobIds, err := redis.RequeueInterruptedUploadJobs()
+	if len(jobIds) > 0 {
+		handleError(nil, fmt.Sprintf("Requeued %d interrupted upload jobs", len(jobIds)), "info")
+	}
+	return err
+}
+
+func uploadWorker(ctx context.Context, workerId string) {
+	//- the first heartbeat is written before any job is taken, the worker is never mistaken for a dead one
+	for redis.RenewUploadWorker(workerId) != nil {
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(uploadJobRetryAfterQueueFailure):
+		}
+	}
+	go uploadWorkerHeartbeat(ctx, workerId)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		default:
+		}
+
+		jobId, err := redis.DequeueUploadJob(workerId, uploadJobDequeueTimeout)
+		if err != nil {
+			time.Sleep(uploadJobRetryAfterQueueFailure)
+			continue
+		}
+		if jobId == "" {
+			continue
+		}
+
+		runUploadJob(jobId)
+		redis.AckUploadJob(workerId, jobId)
+	}
+}
+
+func uploadWorkerHeartbeat(ctx context.Context, workerId string) {
+	ticker := time.NewTicker(redis.UPLOAD_WORKER_EXPIRATION / 3)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			//- tried again on the next tick, the heartbeat is still valid for a while
+			redis.RenewUploadWorker(workerId)
+		}
+	}
+}
+
+func runUploadJob(jobId string) {
+	job, err := redis.GetUploadJob(jobId)
+	if err != nil {
+		handleError(err, fmt.Sprintf("Error when load upload job %s", jobId), "error")
+		return
+	}
+	if job.Finished() {
+		return
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			failUploadJob(job, fmt.Errorf("upload job panicked: %v", r))
+		}
+	}()
+
+	//- a job interrupted after youtube accepted the video must not upload it twice
+	if job.VideoId == "" {
+		if err = uploadJobVideo(job); err != nil {
+			if errors.Is(err, ErrQuotaExceeded) {
+				deferUploadJob(job, err)
+				return
+			}
+			failUploadJob(job, err)
+			return
+		}
+		//- the video id is kept before the next steps, a job interrupted from here on never uploads the video again
+		redis.SaveUploadJob(job)
+	}
+
+	//- a thumbnail or playlist refused by youtube does not fail the video, the error is kept on the job
+	if job.ThumbnailPath != "" && job.ThumbnailError == "" {
+		if err = setJobThumbnail(job); err != nil {
+			job.ThumbnailError = err.Error()
+		}
+	}
+	if job.Metadata != nil && job.Metadata.PlaylistId != "" && job.PlaylistItemId == "" && job.PlaylistError == "" {
+		if err = addJobPlaylistItem(job); err != nil {
+			job.PlaylistError = err.Error()
+		}
+	}
+
+	job.Status = domain.UploadJobProcessing
+	redis.SaveUploadJob(job)
+
+	//- only youtube refusing the video fails the job, the video is on youtube already
+	if err = waitForProcessing(job); err != nil {
+		failUploadJob(job, err)
+		return
+	}
+
+	job.Status = domain.UploadJobDone
+	job.VideoURL = fmt.Sprintf("https://www.youtube.com/watch?v=%s", job.VideoId)
+	redis.SaveUploadJob(job)
+	removeJobFile(job)
+}
+
+func uploadJobVideo(job *domain.UploadJob) error {
+	file, err := os.Open(job.FilePath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	job.Status = domain.UploadJobUploading
+	job.BytesUploaded = 0
+	job.DeferredUntil = nil
+	job.Error = ""
+	redis.SaveUploadJob(job)
+
+	videoId, err := YoutubeVideoUploadFile(file, job.ClientKey, job.Metadata, job.FileInfo, func(current, total int64) {
+		job.BytesUploaded = current
+		redis.SaveUploadJob(job)
+	})
+	if err != nil {
+		return err
+	}
+
+	job.VideoId = videoId
+	job.BytesUploaded = job.BytesTotal
+	return nil
+}
+
+// - waitForProcessing polls videos.list?part=processingDetails until the uploaded video is processed or the attempts run out,
+// - every poll is saved on the job so watchers see the progress. A video still processing after that is reported as done.
+// - Only a video rejected by youtube is an error, a failed poll is tried again on the next attempt.
+func waitForProcessing(job *domain.UploadJob) error {
+	interval := viper.GetDuration("YOUTUBE.PROCESSING_POLL_INTERVAL")
+	if interval <= 0 {
+		interval = defaultProcessingPollInterval
+	}
+	attempts := viper.GetInt("YOUTUBE.PROCESSING_POLL_ATTEMPTS")
+	if attempts <= 0 {
+		attempts = defaultProcessingPollAttempts
+	}
+
+	service, err := BuildServiceFromToken(job.ClientKey)
+	if err != nil {
+		handleError(err, fmt.Sprintf("Unable to follow the processing of upload job %s", job.Id), "error")
+		return nil
+	}
+	for attempt := 0; attempt < attempts; attempt++ {
+		if attempt > 0 {
+			time.Sleep(interval)
+		}
+
+		response, err := service.Videos.List([]string{"status", "processingDetails"}).Id(job.VideoId).Do()
+		if errors.Is(err, ErrQuotaExceeded) {
+			//- running out of quota only stops the polls
+			return nil
+		}
+		if err != nil {
+			handleError(err, fmt.Sprintf("Unable to read the processing of upload job %s", job.Id), "error")
+			continue
+		}
+		//- a video just uploaded may not be listed yet
+		if len(response.Items) == 0 {
+			continue
+		}
+
+		video := response.Items[0]
+		if video.Status != nil && (video.Status.UploadStatus == "rejected" || video.Status.UploadStatus == "failed") {
+			return fmt.Errorf("youtube %s the video: %s%s", video.Status.UploadStatus, video.Status.RejectionReason, video.Status.FailureReason)
+		}
+		if video.ProcessingDetails != nil {
+			job.ProcessingStatus = video.ProcessingDetails.ProcessingStatus
+			job.ProcessingProgress = video.ProcessingDetails.ProcessingProgress
+			redis.SaveUploadJob(job)
+
+			switch job.ProcessingStatus {
+			case "failed", "terminated":
+				return fmt.Errorf("youtube processing %s: %s", job.ProcessingStatus, video.ProcessingDetails.ProcessingFailureReason)
+			case "succeeded":
+				return nil
+			}
+		}
+	}
+	return nil
+}
+
+// - deferUploadJob puts a job refused for lack of quota back in the queue once the quota is reset, the file is kept
+func deferUploadJob(job *domain.UploadJob, err error) {
+	handleError(err, fmt.Sprintf("Upload job %s deferred until the youtube quota is reset", job.Id), "warn")
+	resetAt := domain.YoutubeQuotaResetAt(time.Now())
+	job.Status = domain.UploadJobQueued
+	job.BytesUploaded = 0
+	job.DeferredUntil = &resetAt
+	job.Error = err.Error()
+	redis.SaveUploadJob(job)
+	if err = redis.DeferUploadJob(job.Id, resetAt); err != nil {
+		failUploadJob(job, err)
+	}
+}
+
+// - RequeueDeferredUploadJobs queues again the jobs deferred until a quota reset which has passed
+func RequeueDeferredUploadJobs() error {
+	jobIds, err := redis.RequeueDeferredUploadJobs(time.Now())
+	if len(jobIds) > 0 {
+		handleError(nil, fmt.Sprintf("Requeued %d upload jobs deferred for quota", len(jobIds)), "info")
+	}
+	return err
+}
+
+func failUploadJob(job *domain.UploadJob, err error) {
+	handleError(err, fmt.Sprintf("Upload job %s failed", job.Id), "error")
+	job.Status = domain.UploadJobFailed
+	job.Error = err.Error()
+	redis.SaveUploadJob(job)
+	removeJobFile(job)
+}
+
+func removeJobFile(job *domain.UploadJob) {
+	for _, filePath := range []string{job.FilePath, job.ThumbnailPath} {
+		if filePath == "" {
+			continue
+		}
+		if err := os.Remove(filePath); err != nil && !errors.Is(err, os.ErrNotExist) {
+			handleError(err, fmt.Sprintf("Error when remove file of upload job %s", job.Id), "warn")
+		}
+	}
+}
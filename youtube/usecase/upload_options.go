@@ -0,0 +1,38 @@
+package usecase
+
+import (
+	"time"
+
+	"github.com/spf13/viper"
+	"google.golang.org/api/googleapi"
+)
+
+const (
+	MB = 1 << 20
+
+	defaultUploadChunkSize          = 16 * MB
+	defaultUploadChunkRetryDeadline = 2 * time.Minute
+)
+
+// - uploadMediaOptions switches videos.insert to the resumable protocol: the video is sent in chunks
+// - and an interrupted chunk is retried with backoff until the retry deadline passes
+func uploadMediaOptions(contentType string) []googleapi.MediaOption {
+	chunkSize := viper.GetInt("YOUTUBE.UPLOAD_CHUNK_SIZE_MB") * MB
+	if chunkSize <= 0 {
+		chunkSize = defaultUploadChunkSize
+	}
+	retryDeadline := viper.GetDuration("YOUTUBE.UPLOAD_CHUNK_RETRY_DEADLINE")
+	if retryDeadline <= 0 {
+		retryDeadline = defaultUploadChunkRetryDeadline
+	}
+
+	options := []googleapi.MediaOption{
+		//- rounded up by the client to a multiple of 256KB
+		googleapi.ChunkSize(chunkSize),
+		googleapi.ChunkRetryDeadline(retryDeadline),
+	}
+	if contentType != "" {
+		options = append(options, googleapi.ContentType(contentType))
+	}
+	return options
+}
@@ -0,0 +1,145 @@
+package usecase
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+	"tiktok_api/app/utils"
+	"tiktok_api/domain"
+
+	"golang.org/x/exp/slices"
+	"google.golang.org/api/googleapi"
+	"google.golang.org/api/youtube/v3"
+)
+
+var ErrCaptionNotFound = errors.New("youtube caption not found")
+
+func YoutubeCaptionList(clientKey string, videoId string) ([]*youtube.Caption, error) {
+	service, err := BuildServiceFromToken(clientKey)
+	if err != nil {
+		return nil, err
+	}
+	response, err := service.Captions.List([]string{"snippet"}, videoId).Do()
+	if err != nil {
+		handleError(err, fmt.Sprintf("Error when list captions of youtube video %s", videoId), "error")
+		return nil, notFoundError(err, ErrVideoNotFound)
+	}
+	return response.Items, nil
+}
+
+// - YoutubeCaptionUpload adds a caption track to the video, caption is an SRT or WebVTT file
+func YoutubeCaptionUpload(clientKey string, videoId string, payload *domain.YoutubeCaptionPayload, caption []byte) (*youtube.Caption, error) {
+	if err := payload.Validate(); err != nil {
+		return nil, err
+	}
+	format, err := utils.ValidateCaption(caption)
+	if err != nil {
+		return nil, domain.Invalid(err)
+	}
+	service, err := BuildServiceFromToken(clientKey)
+	if err != nil {
+		return nil, err
+	}
+	track := &youtube.Caption{
+		Snippet: &youtube.CaptionSnippet{
+			VideoId:  videoId,
+			Language: payload.Language,
+			Name:     payload.Name,
+			IsDraft:  payload.IsDraft,
+		},
+	}
+	track, err = service.Captions.Insert([]string{"snippet"}, track).
+		Media(bytes.NewReader(caption), googleapi.ContentType(utils.CaptionContentType[format])).Do()
+	if err != nil {
+		handleError(err, fmt.Sprintf("Error when upload caption of youtube video %s", videoId), "error")
+		return nil, notFoundError(err, ErrVideoNotFound)
+	}
+	return track, nil
+}
+
+// - YoutubeCaptionReplace uploads a new file for an existing track, isDraft is left unchanged when nil
+func YoutubeCaptionReplace(clientKey string, videoId string, captionId string, caption []byte, isDraft *bool) (*youtube.Caption, error) {
+	format, err := utils.ValidateCaption(caption)
+	if err != nil {
+		return nil, domain.Invalid(err)
+	}
+	service, err := BuildServiceFromToken(clientKey)
+	if err != nil {
+		return nil, err
+	}
+	track, err := getCaption(service, videoId, captionId)
+	if err != nil {
+		return nil, err
+	}
+
+	update := &youtube.Caption{Id: captionId, Snippet: &youtube.CaptionSnippet{IsDraft: track.Snippet.IsDraft}}
+	if isDraft != nil {
+		update.Snippet.IsDraft = *isDraft
+	}
+	//- false is dropped by the client unless forced
+	update.Snippet.ForceSendFields = []string{"IsDraft"}
+
+	track, err = service.Captions.Update([]string{"snippet"}, update).
+		Media(bytes.NewReader(caption), googleapi.ContentType(utils.CaptionContentType[format])).Do()
+	if err != nil {
+		handleError(err, fmt.Sprintf("Error when replace youtube caption %s", captionId), "error")
+		return nil, notFoundError(err, ErrCaptionNotFound)
+	}
+	return track, nil
+}
+
+// - YoutubeCaptionDownload returns the response of captions.download, the caller closes its body.
+// - An empty format keeps the format the track was uploaded in.
+func YoutubeCaptionDownload(clientKey string, videoId string, captionId string, format string) (*http.Response, error) {
+	if format != "" && !slices.Contains(domain.YoutubeCaptionFormats, format) {
+		return nil, domain.Invalidf("format must be one of %v", domain.YoutubeCaptionFormats)
+	}
+	service, err := BuildServiceFromToken(clientKey)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := getCaption(service, videoId, captionId); err != nil {
+		return nil, err
+	}
+
+	call := service.Captions.Download(captionId)
+	if format != "" {
+		call = call.Tfmt(format)
+	}
+	response, err := call.Download()
+	if err != nil {
+		handleError(err, fmt.Sprintf("Error when download youtube caption %s", captionId), "error")
+		return nil, notFoundError(err, ErrCaptionNotFound)
+	}
+	return response, nil
+}
+
+func YoutubeCaptionDelete(clientKey string, videoId string, captionId string) error {
+	service, err := BuildServiceFromToken(clientKey)
+	if err != nil {
+		return err
+	}
+	if _, err := getCaption(service, videoId, captionId); err != nil {
+		return err
+	}
+
+	if err := service.Captions.Delete(captionId).Do(); err != nil {
+		handleError(err, fmt.Sprintf("Error when delete youtube caption %s", captionId), "error")
+		return notFoundError(err, ErrCaptionNotFound)
+	}
+	return nil
+}
+
+// - getCaption makes sure the track belongs to the video of the url
+func getCaption(service *youtube.Service, videoId string, captionId string) (*youtube.Caption, error) {
+	response, err := service.Captions.List([]string{"snippet"}, videoId).Id(captionId).Do()
+	if err != nil {
+		handleError(err, fmt.Sprintf("Error when get youtube caption %s", captionId), "error")
+		return nil, notFoundError(err, ErrVideoNotFound)
+	}
+	if len(response.Items) == 0 {
+		return nil, ErrCaptionNotFound
+	}
+	return response.Items[0], nil
+}
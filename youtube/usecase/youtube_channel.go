@@ -0,0 +1,104 @@
+package usecase
+
+import (
+	"errors"
+	"tiktok_api/domain"
+	"tiktok_api/youtube/repository/redis"
+	"time"
+
+	"github.com/spf13/viper"
+	"google.golang.org/api/youtube/v3"
+)
+
+var ErrChannelNotFound = errors.New("youtube channel not found")
+
+const defaultChannelCacheTTL = time.Hour
+
+var channelParts = []string{"snippet", "statistics", "contentDetails"}
+
+// - YoutubeChannelOverview returns the channel of the client key, read from redis unless refresh is set or the cache expired
+func YoutubeChannelOverview(clientKey string, refresh bool) (*domain.YoutubeChannel, error) {
+	if _, err := getClient(clientKey); err != nil {
+		return nil, err
+	}
+
+	if !refresh {
+		channel, err := redis.GetChannel(clientKey)
+		if err == nil {
+			return channel, nil
+		}
+		if !errors.Is(err, redis.ErrChannelNotCached) {
+			return nil, err
+		}
+	}
+
+	service, err := BuildServiceFromToken(clientKey)
+	if err != nil {
+		return nil, err
+	}
+	response, err := ChannelsListMine(service, channelParts...)
+	if err != nil {
+		return nil, err
+	}
+	if len(response.Items) == 0 {
+		return nil, ErrChannelNotFound
+	}
+
+	channel := domain.NewYoutubeChannel(response.Items[0])
+	ttl := viper.GetDuration("YOUTUBE.CHANNEL_CACHE_TTL")
+	if ttl <= 0 {
+		ttl = defaultChannelCacheTTL
+	}
+	if _, err = redis.SaveChannel(clientKey, channel, ttl); err != nil {
+		return nil, err
+	}
+	return channel, nil
+}
+
+// - YoutubeChannelUploads returns one page of the uploads playlist of the channel, newest first
+func YoutubeChannelUploads(clientKey string, cursor string, limit int64) (*domain.YoutubePlaylistItemPage, error) {
+	channel, err := YoutubeChannelOverview(clientKey, false)
+	if err != nil {
+		return nil, err
+	}
+	if channel.UploadsPlaylistId == "" {
+		return nil, ErrPlaylistNotFound
+	}
+	service, err := BuildServiceFromToken(clientKey)
+	if err != nil {
+		return nil, err
+	}
+	return playlistItemPage(service, channel.UploadsPlaylistId, cursor, limit)
+}
+
+// - mineChannelId returns the channel the client key was authorized for
+func mineChannelId(service *youtube.Service) (string, error) {
+	response, err := ChannelsListMine(service, "id")
+	if err != nil {
+		return "", err
+	}
+	if len(response.Items) == 0 {
+		return "", ErrChannelNotFound
+	}
+	return response.Items[0].Id, nil
+}
+
+// Retrieve resource for the channel of the given username
+func ChannelsListByUsername(service *youtube.Service, forUsername string, parts ...string) (*youtube.ChannelListResponse, error) {
+	response, err := service.Channels.List(parts).ForUsername(forUsername).Do()
+	if err != nil {
+		handleError(err, "Error when call service.Channels.List()", "error")
+		return nil, err
+	}
+	return response, nil
+}
+
+// Retrieve resource for the authenticated user's channel
+func ChannelsListMine(service *youtube.Service, parts ...string) (*youtube.ChannelListResponse, error) {
+	response, err := service.Channels.List(parts).Mine(true).Do()
+	if err != nil {
+		handleError(err, "Cannot get channelsListMine", "error")
+		return nil, err
+	}
+	return response, nil
+}
@@ -0,0 +1,213 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"tiktok_api/app/utils"
+	"tiktok_api/domain"
+	"tiktok_api/youtube/repository/redis"
+
+	"google.golang.org/api/youtube/v3"
+)
+
+var ErrCommentNotFound = errors.New("youtube comment not found")
+
+const (
+	commentPageDefaultSize = 20
+	commentPageMaxSize     = 100
+	//- an incremental fetch costs one quota unit per page, older new threads are left for the next fetch
+	commentIncrementalMaxPages = 5
+)
+
+var commentThreadParts = []string{"snippet", "replies"}
+
+// - YoutubeCommentThreadList returns one page of the threads of a video, or of the whole channel when videoId is empty.
+// - An empty channelId means the channel of the client key.
+func YoutubeCommentThreadList(clientKey string, videoId string, channelId string, cursor string, limit int64) (*domain.YoutubeCommentThreadPage, error) {
+	pageToken, err := utils.DecodeCursor(cursor)
+	if err != nil {
+		return nil, domain.Invalid(err)
+	}
+	if limit <= 0 {
+		limit = commentPageDefaultSize
+	}
+	if limit > commentPageMaxSize {
+		limit = commentPageMaxSize
+	}
+
+	service, err := BuildServiceFromToken(clientKey)
+	if err != nil {
+		return nil, err
+	}
+	call, _, err := commentThreadsCall(service, videoId, channelId)
+	if err != nil {
+		return nil, err
+	}
+	call = call.MaxResults(limit)
+	if pageToken != "" {
+		call = call.PageToken(pageToken)
+	}
+	response, err := call.Do()
+	if err != nil {
+		handleError(err, "Error when list youtube comment threads", "error")
+		return nil, notFoundError(err, ErrVideoNotFound)
+	}
+
+	return &domain.YoutubeCommentThreadPage{
+		Items:      response.Items,
+		NextCursor: utils.EncodeCursor(response.NextPageToken),
+	}, nil
+}
+
+// - YoutubeCommentThreadListSince returns the threads posted since the previous incremental fetch of the same video or channel.
+// - The first fetch returns the latest threads and starts the watermark kept in redis.
+func YoutubeCommentThreadListSince(clientKey string, videoId string, channelId string) (*domain.YoutubeCommentThreadPage, error) {
+	service, err := BuildServiceFromToken(clientKey)
+	if err != nil {
+		return nil, err
+	}
+	call, scope, err := commentThreadsCall(service, videoId, channelId)
+	if err != nil {
+		return nil, err
+	}
+	watermark, err := redis.GetCommentWatermark(clientKey, scope)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &domain.YoutubeCommentThreadPage{Items: []*youtube.CommentThread{}}
+	if !watermark.PublishedAt.IsZero() {
+		page.Since = &watermark.PublishedAt
+	}
+
+	//- newest first, so paging stops at the first thread older than the watermark
+	call = call.Order("time").MaxResults(commentPageMaxSize)
+	next := &domain.YoutubeCommentWatermark{PublishedAt: watermark.PublishedAt, ThreadIds: watermark.ThreadIds}
+	if watermark.PageToken != "" && watermark.Next != nil {
+		//- a truncated fetch left older threads behind, the newest ones were returned already
+		call = call.PageToken(watermark.PageToken)
+		next = watermark.Next
+	}
+	pageToken := ""
+	pages := 0
+	for {
+		response, err := call.Do()
+		if err != nil {
+			handleError(err, "Error when list youtube comment threads", "error")
+			return nil, notFoundError(err, ErrVideoNotFound)
+		}
+		pages++
+
+		reachedWatermark := false
+		for _, thread := range response.Items {
+			publishedAt, err := domain.CommentThreadPublishedAt(thread)
+			if err != nil {
+				handleError(err, "Error when read youtube comment thread", "warn")
+				continue
+			}
+			if publishedAt.Before(watermark.PublishedAt) {
+				reachedWatermark = true
+				break
+			}
+			//- threads of the same second come in no particular order
+			if watermark.Returned(thread.Id, publishedAt) {
+				continue
+			}
+			next.Add(thread.Id, publishedAt)
+			page.Items = append(page.Items, thread)
+		}
+
+		if reachedWatermark || response.NextPageToken == "" {
+			break
+		}
+		if pages == commentIncrementalMaxPages {
+			page.Truncated = true
+			pageToken = response.NextPageToken
+			break
+		}
+		call = call.PageToken(response.NextPageToken)
+	}
+
+	//- the watermark only moves once every thread since it was returned, the first fetch never looks further back
+	if page.Truncated && !watermark.PublishedAt.IsZero() {
+		next = &domain.YoutubeCommentWatermark{
+			PublishedAt: watermark.PublishedAt,
+			ThreadIds:   watermark.ThreadIds,
+			PageToken:   pageToken,
+			Next:        next,
+		}
+	}
+	if len(page.Items) > 0 || watermark.PageToken != "" {
+		if _, err = redis.SaveCommentWatermark(clientKey, scope, next); err != nil {
+			return nil, err
+		}
+	}
+	return page, nil
+}
+
+// - commentThreadsCall selects the threads of the video or of the channel, scope names the selection in redis
+func commentThreadsCall(service *youtube.Service, videoId string, channelId string) (*youtube.CommentThreadsListCall, string, error) {
+	call := service.CommentThreads.List(commentThreadParts).TextFormat("plainText")
+	if videoId != "" {
+		return call.VideoId(videoId), fmt.Sprintf("video_%s", videoId), nil
+	}
+
+	if channelId == "" {
+		var err error
+		if channelId, err = mineChannelId(service); err != nil {
+			return nil, "", err
+		}
+	}
+	return call.AllThreadsRelatedToChannelId(channelId), fmt.Sprintf("channel_%s", channelId), nil
+}
+
+// - YoutubeCommentReply answers a top level comment, commentId is also the id of its thread
+func YoutubeCommentReply(clientKey string, commentId string, payload *domain.YoutubeCommentReplyPayload) (*youtube.Comment, error) {
+	if err := payload.Validate(); err != nil {
+		return nil, err
+	}
+	service, err := BuildServiceFromToken(clientKey)
+	if err != nil {
+		return nil, err
+	}
+	comment, err := service.Comments.Insert([]string{"snippet"}, &youtube.Comment{
+		Snippet: &youtube.CommentSnippet{
+			ParentId:     commentId,
+			TextOriginal: payload.Text,
+		},
+	}).Do()
+	if err != nil {
+		handleError(err, fmt.Sprintf("Error when reply to youtube comment %s", commentId), "error")
+		return nil, notFoundError(err, ErrCommentNotFound)
+	}
+	return comment, nil
+}
+
+// - YoutubeCommentModerate holds a comment for review or publishes it, only the channel owner can do it
+func YoutubeCommentModerate(clientKey string, commentId string, payload *domain.YoutubeCommentModerationPayload) error {
+	status, err := payload.ModerationStatus()
+	if err != nil {
+		return err
+	}
+	service, err := BuildServiceFromToken(clientKey)
+	if err != nil {
+		return err
+	}
+	if err = service.Comments.SetModerationStatus([]string{commentId}, status).Do(); err != nil {
+		handleError(err, fmt.Sprintf("Error when moderate youtube comment %s", commentId), "error")
+		return notFoundError(err, ErrCommentNotFound)
+	}
+	return nil
+}
+
+func YoutubeCommentDelete(clientKey string, commentId string) error {
+	service, err := BuildServiceFromToken(clientKey)
+	if err != nil {
+		return err
+	}
+	if err := service.Comments.Delete(commentId).Do(); err != nil {
+		handleError(err, fmt.Sprintf("Error when delete youtube comment %s", commentId), "error")
+		return notFoundError(err, ErrCommentNotFound)
+	}
+	return nil
+}
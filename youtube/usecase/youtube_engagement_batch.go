@@ -0,0 +1,100 @@
+package usecase
+
+import (
+	"fmt"
+	"tiktok_api/domain"
+	"tiktok_api/youtube/repository/redis"
+	"time"
+
+	"google.golang.org/api/youtube/v3"
+)
+
+// - YoutubeVideoEngagementBatch answers from redis first and asks youtube for the rest, 50 videos per videos.list call.
+// - Stale entries are still answered and refreshed in the background.
+// - Results keep the order of the request, a failed call only fails the videos it was asked for.
+func YoutubeVideoEngagementBatch(payload *domain.YoutubeEngagementBatchPayload) ([]*domain.YoutubeEngagementResult, error) {
+	if err := payload.Validate(); err != nil {
+		return nil, err
+	}
+	clientKey := payload.ClientKey
+	if _, err := getClient(clientKey); err != nil {
+		return nil, err
+	}
+
+	videoIds := payload.UniqueVideoIds()
+	cached, err := redis.GetVideoEngagementInfos(clientKey, videoIds)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	results := make(map[string]*domain.YoutubeEngagementResult, len(videoIds))
+	misses := []string{}
+	for _, videoId := range videoIds {
+		if engagement, ok := cached[videoId]; ok {
+			if engagement.Stale(now) {
+				revalidateVideoEngagement(clientKey, videoId)
+			}
+			results[videoId] = &domain.YoutubeEngagementResult{VideoId: videoId, Status: domain.YoutubeEngagementFound, Statistics: engagement.Statistics, Cached: true}
+			continue
+		}
+		misses = append(misses, videoId)
+	}
+
+	if len(misses) > 0 {
+		service, err := BuildServiceFromToken(clientKey)
+		if err != nil {
+			return nil, err
+		}
+		for start := 0; start < len(misses); start += domain.YoutubeVideosListMaxIds {
+			end := start + domain.YoutubeVideosListMaxIds
+			if end > len(misses) {
+				end = len(misses)
+			}
+			batch := misses[start:end]
+
+			statistics, err := listVideoStatistics(service, batch)
+			if err != nil {
+				for _, videoId := range batch {
+					results[videoId] = &domain.YoutubeEngagementResult{VideoId: videoId, Status: domain.YoutubeEngagementError, Error: err.Error()}
+				}
+				continue
+			}
+
+			for videoId, videoStatistics := range statistics {
+				if _, err = cacheVideoEngagement(clientKey, videoId, videoStatistics, EngagementMaxAge(), redis.ENGAGEMENT_EXPIRATION); err != nil {
+					handleError(err, fmt.Sprintf("Error when cache engagement of youtube video %s", videoId), "warn")
+				}
+				results[videoId] = &domain.YoutubeEngagementResult{VideoId: videoId, Status: domain.YoutubeEngagementFound, Statistics: videoStatistics}
+			}
+		}
+	}
+
+	ordered := make([]*domain.YoutubeEngagementResult, 0, len(videoIds))
+	for _, videoId := range videoIds {
+		result, ok := results[videoId]
+		if !ok {
+			//- youtube leaves deleted, private and invalid ids out of the response
+			result = &domain.YoutubeEngagementResult{VideoId: videoId, Status: domain.YoutubeEngagementNotFound}
+		}
+		ordered = append(ordered, result)
+	}
+	return ordered, nil
+}
+
+// - listVideoStatistics reads the statistics of at most 50 videos in one call, videos youtube does not return are left out
+func listVideoStatistics(service *youtube.Service, videoIds []string) (map[string]*youtube.VideoStatistics, error) {
+	response, err := service.Videos.List([]string{"statistics"}).Id(videoIds...).MaxResults(int64(len(videoIds))).Do()
+	if err != nil {
+		handleError(err, fmt.Sprintf("Error when list engagement of %d youtube videos", len(videoIds)), "error")
+		return nil, err
+	}
+
+	statistics := make(map[string]*youtube.VideoStatistics, len(response.Items))
+	for _, video := range response.Items {
+		if video.Statistics != nil {
+			statistics[video.Id] = video.Statistics
+		}
+	}
+	return statistics, nil
+}
@@ -0,0 +1,34 @@
+package usecase
+
+import (
+	"tiktok_api/domain"
+	"tiktok_api/youtube/repository/redis"
+	"time"
+)
+
+// - YoutubeVideoEngagementHistory returns the statistics recorded between from and to, one point per interval
+func YoutubeVideoEngagementHistory(clientKey string, videoId string, from time.Time, to time.Time, interval string) (*domain.YoutubeEngagementHistory, error) {
+	if !from.Before(to) {
+		return nil, domain.Invalidf("from must be before to")
+	}
+	step, err := domain.ParseEngagementInterval(interval)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := getClient(clientKey); err != nil {
+		return nil, err
+	}
+
+	snapshots, err := redis.GetEngagementSnapshots(clientKey, videoId, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.YoutubeEngagementHistory{
+		VideoId:  videoId,
+		From:     from,
+		To:       to,
+		Interval: interval,
+		Points:   domain.DownsampleEngagement(snapshots, step),
+	}, nil
+}
@@ -0,0 +1,238 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"tiktok_api/app/utils"
+	"tiktok_api/domain"
+
+	"google.golang.org/api/googleapi"
+	"google.golang.org/api/youtube/v3"
+)
+
+var ErrPlaylistNotFound = errors.New("youtube playlist not found")
+var ErrPlaylistItemNotFound = errors.New("youtube playlist item not found")
+
+func YoutubePlaylistCreate(clientKey string, payload *domain.YoutubePlaylistPayload) (*youtube.Playlist, error) {
+	playlist := &youtube.Playlist{}
+	if err := payload.Apply(playlist); err != nil {
+		return nil, err
+	}
+
+	service, err := BuildServiceFromToken(clientKey)
+	if err != nil {
+		return nil, err
+	}
+	playlist, err = service.Playlists.Insert([]string{"snippet", "status"}, playlist).Do()
+	if err != nil {
+		handleError(err, "Error when create youtube playlist", "error")
+		return nil, err
+	}
+	return playlist, nil
+}
+
+// - YoutubePlaylistUpdate changes title, description or privacy, playlists.update replaces the whole snippet so it is read first
+func YoutubePlaylistUpdate(clientKey string, playlistId string, payload *domain.YoutubePlaylistPayload) (*youtube.Playlist, error) {
+	service, err := BuildServiceFromToken(clientKey)
+	if err != nil {
+		return nil, err
+	}
+	response, err := service.Playlists.List([]string{"snippet", "status"}).Id(playlistId).Do()
+	if err != nil {
+		handleError(err, fmt.Sprintf("Error when get youtube playlist %s", playlistId), "error")
+		return nil, err
+	}
+	if len(response.Items) == 0 {
+		return nil, ErrPlaylistNotFound
+	}
+
+	playlist := response.Items[0]
+	if err = payload.Apply(playlist); err != nil {
+		return nil, err
+	}
+
+	playlist, err = service.Playlists.Update([]string{"snippet", "status"}, playlist).Do()
+	if err != nil {
+		handleError(err, fmt.Sprintf("Error when update youtube playlist %s", playlistId), "error")
+		return nil, notFoundError(err, ErrPlaylistNotFound)
+	}
+	return playlist, nil
+}
+
+// - YoutubePlaylistDelete removes the playlist, the videos in it stay on the channel
+func YoutubePlaylistDelete(clientKey string, playlistId string) error {
+	service, err := BuildServiceFromToken(clientKey)
+	if err != nil {
+		return err
+	}
+	if err := service.Playlists.Delete(playlistId).Do(); err != nil {
+		handleError(err, fmt.Sprintf("Error when delete youtube playlist %s", playlistId), "error")
+		return notFoundError(err, ErrPlaylistNotFound)
+	}
+	return nil
+}
+
+// - YoutubePlaylistItemList returns one page of the playlist, the youtube page token is wrapped in an opaque cursor
+func YoutubePlaylistItemList(clientKey string, playlistId string, cursor string, limit int64) (*domain.YoutubePlaylistItemPage, error) {
+	service, err := BuildServiceFromToken(clientKey)
+	if err != nil {
+		return nil, err
+	}
+	return playlistItemPage(service, playlistId, cursor, limit)
+}
+
+func playlistItemPage(service *youtube.Service, playlistId string, cursor string, limit int64) (*domain.YoutubePlaylistItemPage, error) {
+	pageToken, err := utils.DecodeCursor(cursor)
+	if err != nil {
+		return nil, domain.Invalid(err)
+	}
+	if limit <= 0 {
+		limit = domain.YoutubePlaylistPageDefaultSize
+	}
+	if limit > domain.YoutubePlaylistPageMaxSize {
+		limit = domain.YoutubePlaylistPageMaxSize
+	}
+
+	response, err := PlaylistItemsList(service, playlistId, pageToken, limit)
+	if err != nil {
+		handleError(err, fmt.Sprintf("Error when list items of youtube playlist %s", playlistId), "error")
+		return nil, notFoundError(err, ErrPlaylistNotFound)
+	}
+
+	page := &domain.YoutubePlaylistItemPage{
+		Items:      response.Items,
+		NextCursor: utils.EncodeCursor(response.NextPageToken),
+		PrevCursor: utils.EncodeCursor(response.PrevPageToken),
+	}
+	if response.PageInfo != nil {
+		page.TotalResults = response.PageInfo.TotalResults
+	}
+	return page, nil
+}
+
+// Retrieve playlistItems in the specified playlist
+func PlaylistItemsList(service *youtube.Service, playlistId string, pageToken string, maxResults int64) (*youtube.PlaylistItemListResponse, error) {
+	call := service.PlaylistItems.List([]string{"snippet", "contentDetails"})
+	call = call.PlaylistId(playlistId).MaxResults(maxResults)
+	if pageToken != "" {
+		call = call.PageToken(pageToken)
+	}
+	return call.Do()
+}
+
+// - YoutubePlaylistItemAdd puts the video in the playlist, at the end unless a position is given
+func YoutubePlaylistItemAdd(clientKey string, playlistId string, payload *domain.YoutubePlaylistItemPayload) (*youtube.PlaylistItem, error) {
+	if payload.VideoId == "" {
+		return nil, domain.Invalidf("video id is required")
+	}
+	service, err := BuildServiceFromToken(clientKey)
+	if err != nil {
+		return nil, err
+	}
+	item, err := addPlaylistItem(service, playlistId, payload.VideoId, payload.Position)
+	if err != nil {
+		handleError(err, fmt.Sprintf("Error when add video %s to youtube playlist %s", payload.VideoId, playlistId), "error")
+		return nil, notFoundError(err, ErrPlaylistNotFound)
+	}
+	return item, nil
+}
+
+func addPlaylistItem(service *youtube.Service, playlistId string, videoId string, position *int64) (*youtube.PlaylistItem, error) {
+	item := &youtube.PlaylistItem{
+		Snippet: &youtube.PlaylistItemSnippet{
+			PlaylistId: playlistId,
+			ResourceId: &youtube.ResourceId{
+				Kind:    "youtube#video",
+				VideoId: videoId,
+			},
+		},
+	}
+	if position != nil {
+		item.Snippet.Position = *position
+		//- position 0 is dropped by the client unless forced
+		item.Snippet.ForceSendFields = []string{"Position"}
+	}
+	return service.PlaylistItems.Insert([]string{"snippet"}, item).Do()
+}
+
+// - addJobPlaylistItem appends the video of the upload job to the playlist chosen in its metadata
+func addJobPlaylistItem(job *domain.UploadJob) error {
+	service, err := BuildServiceFromToken(job.ClientKey)
+	if err != nil {
+		return err
+	}
+	item, err := addPlaylistItem(service, job.Metadata.PlaylistId, job.VideoId, nil)
+	if err != nil {
+		return err
+	}
+	job.PlaylistItemId = item.Id
+	return nil
+}
+
+// - YoutubePlaylistItemMove changes the position of an item, the other items shift around it
+func YoutubePlaylistItemMove(clientKey string, playlistId string, itemId string, position int64) (*youtube.PlaylistItem, error) {
+	if position < 0 {
+		return nil, domain.Invalidf("position must not be negative")
+	}
+	service, err := BuildServiceFromToken(clientKey)
+	if err != nil {
+		return nil, err
+	}
+	response, err := service.PlaylistItems.List([]string{"snippet"}).Id(itemId).Do()
+	if err != nil {
+		handleError(err, fmt.Sprintf("Error when get youtube playlist item %s", itemId), "error")
+		return nil, err
+	}
+	if len(response.Items) == 0 || response.Items[0].Snippet.PlaylistId != playlistId {
+		return nil, ErrPlaylistItemNotFound
+	}
+
+	current := response.Items[0].Snippet
+	item := &youtube.PlaylistItem{
+		Id: itemId,
+		Snippet: &youtube.PlaylistItemSnippet{
+			PlaylistId:      playlistId,
+			ResourceId:      current.ResourceId,
+			Position:        position,
+			ForceSendFields: []string{"Position"},
+		},
+	}
+	item, err = service.PlaylistItems.Update([]string{"snippet"}, item).Do()
+	if err != nil {
+		handleError(err, fmt.Sprintf("Error when move youtube playlist item %s", itemId), "error")
+		return nil, notFoundError(err, ErrPlaylistItemNotFound)
+	}
+	return item, nil
+}
+
+// - YoutubePlaylistItemRemove takes the item out of the playlist, itemId is the playlist item id and not the video id
+func YoutubePlaylistItemRemove(clientKey string, playlistId string, itemId string) error {
+	service, err := BuildServiceFromToken(clientKey)
+	if err != nil {
+		return err
+	}
+	response, err := service.PlaylistItems.List([]string{"snippet"}).Id(itemId).Do()
+	if err != nil {
+		handleError(err, fmt.Sprintf("Error when get youtube playlist item %s", itemId), "error")
+		return err
+	}
+	if len(response.Items) == 0 || response.Items[0].Snippet.PlaylistId != playlistId {
+		return ErrPlaylistItemNotFound
+	}
+
+	if err = service.PlaylistItems.Delete(itemId).Do(); err != nil {
+		handleError(err, fmt.Sprintf("Error when remove youtube playlist item %s", itemId), "error")
+		return notFoundError(err, ErrPlaylistItemNotFound)
+	}
+	return nil
+}
+
+// - notFoundError turns the 404 of youtube into the given sentinel error
+func notFoundError(err error, notFound error) error {
+	var apiErr *googleapi.Error
+	if errors.As(err, &apiErr) && apiErr.Code == http.StatusNotFound {
+		return notFound
+	}
+	return err
+}
@@ -0,0 +1,84 @@
+package usecase
+
+import (
+	"errors"
+	"tiktok_api/domain"
+	"tiktok_api/youtube/repository/redis"
+	"time"
+
+	"google.golang.org/api/youtube/v3"
+)
+
+var ErrVideoNotFound = errors.New("youtube video not found")
+
+func YoutubeScheduledVideoList(clientKey string) ([]*domain.YoutubeScheduledVideo, error) {
+	return redis.GetUpcomingScheduledVideos(clientKey)
+}
+
+// - YoutubeVideoReschedule moves the publish time of a scheduled video, the video stays private until then
+func YoutubeVideoReschedule(clientKey string, videoId string, publishAt time.Time) (*domain.YoutubeScheduledVideo, error) {
+	if !publishAt.After(time.Now()) {
+		return nil, domain.Invalidf("publish at must be in the future")
+	}
+
+	scheduledVideo, err := redis.GetScheduledVideo(clientKey, videoId)
+	if err != nil {
+		return nil, err
+	}
+
+	service, err := BuildServiceFromToken(clientKey)
+	if err != nil {
+		return nil, err
+	}
+	err = updateVideoPublishAt(service, videoId, publishAt.UTC().Format(time.RFC3339))
+	if err != nil {
+		handleError(err, "Error when reschedule youtube video", "error")
+		return nil, err
+	}
+
+	scheduledVideo.PublishAt = publishAt
+	_, err = redis.SaveScheduledVideo(clientKey, scheduledVideo)
+	if err != nil {
+		return nil, err
+	}
+	return scheduledVideo, nil
+}
+
+// - YoutubeVideoCancelSchedule clears the publish time, the video is kept on the channel as private
+func YoutubeVideoCancelSchedule(clientKey string, videoId string) error {
+	if _, err := redis.GetScheduledVideo(clientKey, videoId); err != nil {
+		return err
+	}
+
+	service, err := BuildServiceFromToken(clientKey)
+	if err != nil {
+		return err
+	}
+	err = updateVideoPublishAt(service, videoId, "")
+	if err != nil {
+		handleError(err, "Error when cancel youtube video schedule", "error")
+		return err
+	}
+
+	return redis.DeleteScheduledVideo(clientKey, videoId)
+}
+
+// - videos.update replaces the whole status part, so the current status is read first to keep license, embeddable and kids settings
+func updateVideoPublishAt(service *youtube.Service, videoId string, publishAt string) error {
+	response, err := service.Videos.List([]string{"status"}).Id(videoId).Do()
+	if err != nil {
+		return err
+	}
+	if len(response.Items) == 0 {
+		return ErrVideoNotFound
+	}
+
+	status := response.Items[0].Status
+	status.PrivacyStatus = "private"
+	status.PublishAt = publishAt
+	_, err = service.Videos.Update([]string{"status"}, &youtube.Video{
+		Id:     videoId,
+		Status: status,
+	}).Do()
+	return err
+}
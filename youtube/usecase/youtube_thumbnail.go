@@ -0,0 +1,44 @@
+package usecase
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"tiktok_api/app/utils"
+	"tiktok_api/domain"
+
+	"google.golang.org/api/googleapi"
+	"google.golang.org/api/youtube/v3"
+)
+
+// - YoutubeVideoSetThumbnail replaces the thumbnail of the video, only verified channels can use custom thumbnails
+func YoutubeVideoSetThumbnail(clientKey string, videoId string, thumbnail io.Reader, contentType string) (*youtube.ThumbnailDetails, error) {
+	service, err := BuildServiceFromToken(clientKey)
+	if err != nil {
+		return nil, err
+	}
+	response, err := service.Thumbnails.Set(videoId).Media(thumbnail, googleapi.ContentType(contentType)).Do()
+	if err != nil {
+		handleError(err, fmt.Sprintf("Error when set thumbnail of youtube video %s", videoId), "error")
+		return nil, err
+	}
+	if len(response.Items) == 0 {
+		return nil, nil
+	}
+	return response.Items[0], nil
+}
+
+// - setJobThumbnail applies the thumbnail uploaded along with the video of the job
+func setJobThumbnail(job *domain.UploadJob) error {
+	data, err := os.ReadFile(job.ThumbnailPath)
+	if err != nil {
+		return err
+	}
+	contentType, err := utils.DetectThumbnailType(data)
+	if err != nil {
+		return domain.Invalid(err)
+	}
+	_, err = YoutubeVideoSetThumbnail(job.ClientKey, job.VideoId, bytes.NewReader(data), contentType)
+	return err
+}
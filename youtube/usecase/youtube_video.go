@@ -0,0 +1,63 @@
+package usecase
+
+import (
+	"fmt"
+	"tiktok_api/domain"
+	"tiktok_api/youtube/repository/redis"
+
+	"google.golang.org/api/youtube/v3"
+)
+
+// - YoutubeVideoUpdate changes title, description, tags, category and privacy of an uploaded video
+func YoutubeVideoUpdate(clientKey string, videoId string, payload *domain.YoutubeVideoUpdatePayload) (*youtube.Video, error) {
+	service, err := BuildServiceFromToken(clientKey)
+	if err != nil {
+		return nil, err
+	}
+	response, err := service.Videos.List([]string{"snippet", "status"}).Id(videoId).Do()
+	if err != nil {
+		handleError(err, fmt.Sprintf("Error when get youtube video %s", videoId), "error")
+		return nil, err
+	}
+	if len(response.Items) == 0 {
+		return nil, ErrVideoNotFound
+	}
+
+	video := response.Items[0]
+	wasScheduled := video.Status != nil && video.Status.PublishAt != ""
+	if err = payload.Apply(video); err != nil {
+		return nil, err
+	}
+
+	updated, err := service.Videos.Update([]string{"snippet", "status"}, video).Do()
+	if err != nil {
+		handleError(err, fmt.Sprintf("Error when update youtube video %s", videoId), "error")
+		return nil, notFoundError(err, ErrVideoNotFound)
+	}
+
+	//- a video made public or unlisted is not scheduled anymore
+	if wasScheduled && updated.Status.PublishAt == "" {
+		if err = redis.DeleteScheduledVideo(clientKey, videoId); err != nil {
+			return nil, err
+		}
+	}
+	return updated, nil
+}
+
+// - YoutubeVideoDelete removes the video from the channel along with its cached engagement and upload info
+func YoutubeVideoDelete(clientKey string, videoId string) error {
+	service, err := BuildServiceFromToken(clientKey)
+	if err != nil {
+		return err
+	}
+	if err := service.Videos.Delete(videoId).Do(); err != nil {
+		handleError(err, fmt.Sprintf("Error when delete youtube video %s", videoId), "error")
+		return notFoundError(err, ErrVideoNotFound)
+	}
+
+	//- the video is gone from youtube already, leftovers in redis expire or are skipped by the poller
+	if err := redis.DeleteVideoInfo(clientKey, videoId); err != nil {
+		handleError(err, fmt.Sprintf("Unable to delete the info of deleted youtube video %s", videoId), "error")
+	}
+	return nil
+}
@@ -1,17 +1,28 @@
 package usecase
 
 import (
-	"bytes"
 	"fmt"
+	"io"
 	"os"
-	"strings"
 	"tiktok_api/app/logger"
+	"tiktok_api/app/utils"
 	"tiktok_api/domain"
 	"tiktok_api/youtube/repository/redis"
+	"time"
 
+	"golang.org/x/sync/singleflight"
+	"google.golang.org/api/googleapi"
 	"google.golang.org/api/youtube/v3"
 )
 
+const (
+	defaultEngagementMaxAge         = 15 * time.Minute
+	defaultEngagementRefreshBackoff = time.Minute
+)
+
+// - engagement reads of the same video made while youtube is being asked wait for that call instead of making their own
+var engagementRequests singleflight.Group
+
 func handleError(err error, message string, errorType string) {
 	fields := logger.Fields{
 		"service": "Youtube",
@@ -31,79 +42,79 @@ func handleError(err error, message string, errorType string) {
 	}
 }
 
-func YoutubeVideoUploadFile(fileBuffer *bytes.Buffer, clientKey string, ytbFileUploadInfo *domain.YoutubeFileUploadInfo) (string, error) {
-	//- default params
-	title := "This is a test video"
-	description := "This is a test video from Johnathan using Youtube API"
-	category := "22"
-	keywords := "video, test"
-	privacy := "unlisted"
-
-	service := BuildServiceFromToken(clientKey)
-	fmt.Printf("on YoutubeVideoUploadFile service %+v", service)
-
-	//- video uploading using youtube service
-	upload := &youtube.Video{
-		Snippet: &youtube.VideoSnippet{
-			Title:       title,
-			Description: description,
-			CategoryId:  category,
-		},
-		Status: &youtube.VideoStatus{PrivacyStatus: privacy},
+// - YoutubeVideoUploadFile uploads the video with the resumable protocol, progress is reported after every chunk when set.
+// - The publish time was checked when the upload was accepted, a schedule that passed since then publishes the video right away.
+func YoutubeVideoUploadFile(video io.Reader, clientKey string, metadata *domain.YoutubeVideoMetadata, ytbFileUploadInfo *domain.YoutubeFileUploadInfo, progress googleapi.ProgressUpdater) (string, error) {
+	metadata.SetDefaults()
+	if err := metadata.ValidateLimits(); err != nil {
+		return "", err
+	}
+	if metadata.PublishAtPassed() {
+		metadata.PublishNow()
 	}
 
-	//- The API returns a 400 Bad Request response if tags is an empty string.
-	if strings.Trim(keywords, "") != "" {
-		upload.Snippet.Tags = strings.Split(keywords, ",")
+	service, err := BuildServiceFromToken(clientKey)
+	if err != nil {
+		return "", err
 	}
+
+	//- video uploading using youtube service
+	upload := metadata.Video()
 	parts := []string{
 		"snippet",
 		"status",
 	}
 	call := service.Videos.Insert(parts, upload)
+	if progress != nil {
+		call = call.ProgressUpdater(progress)
+	}
 
-	response, err := call.Media(fileBuffer).Do()
+	response, err := call.Media(video, uploadMediaOptions(ytbFileUploadInfo.FileContentType)...).Do()
 	if err != nil {
 		handleError(err, "Cannot upload video with error ", "error")
 		return "", err
 	}
 
 	//- when success, then save youtube file upload info into redis
+	ytbFileUploadInfo.VideoId = response.Id
+	ytbFileUploadInfo.PublishAt = metadata.PublishAt
 	_, err = redis.SaveYoutubeFileUploadInfo(clientKey, ytbFileUploadInfo)
 	if err != nil {
 		handleError(err, "Save youtube upload file failed", "error")
 		return "", err
 	}
+	scheduleEngagementPoll(clientKey, response.Id, ytbFileUploadInfo.CreatedAt)
+
+	if metadata.PublishAt != nil {
+		_, err = redis.SaveScheduledVideo(clientKey, &domain.YoutubeScheduledVideo{
+			VideoId:   response.Id,
+			Title:     metadata.Title,
+			PublishAt: *metadata.PublishAt,
+			CreatedAt: time.Now(),
+		})
+		if err != nil {
+			handleError(err, "Save youtube scheduled video failed", "error")
+			return "", err
+		}
+	}
 	log.Printf("Upload successful! Video ID: %v\n", response.Id)
 	return response.Id, nil
 }
 
-func YoutubeVideoUpload(clientKey string, videoFilePath string) (string, error) {
-	//- default params
-	//- default params
+func YoutubeVideoUpload(clientKey string, videoFilePath string, metadata *domain.YoutubeVideoMetadata) (string, error) {
 	filename := videoFilePath //- upload file path
-	title := "This is a test video"
-	description := "This is a test video from Johnathan using Youtube API"
-	category := "22"
-	keywords := "video, test"
-	privacy := "unlisted"
-
-	service := BuildServiceFromToken(clientKey)
-
-	//- video uploading using youtube service
-	upload := &youtube.Video{
-		Snippet: &youtube.VideoSnippet{
-			Title:       title,
-			Description: description,
-			CategoryId:  category,
-		},
-		Status: &youtube.VideoStatus{PrivacyStatus: privacy},
+	metadata.SetDefaults()
+	if err := metadata.Validate(); err != nil {
+		return "", err
 	}
 
-	// The API returns a 400 Bad Request response if tags is an empty string.
-	if strings.Trim(keywords, "") != "" {
-		upload.Snippet.Tags = strings.Split(keywords, ",")
+	service, err := BuildServiceFromToken(clientKey)
+	if err != nil {
+		return "", err
 	}
+
+	//- video uploading using youtube service
+	upload := metadata.Video()
 	parts := []string{
 		"snippet",
 		"status",
@@ -126,87 +137,105 @@ func YoutubeVideoUpload(clientKey string, videoFilePath string) (string, error)
 	return response.Id, nil
 }
 
-// - get current video engagement
-func YoutubeVideoEngagement(clientKey string, videoId string) (*youtube.VideoStatistics, error) {
-	service := BuildServiceFromToken(clientKey)
-	parts := []string{
-		"id",
-		"snippet",
-		"statistics",
+// - YoutubeVideoEngagement answers from redis, stale engagement is served while one refresh runs in the background
+// - and concurrent misses of the same video share a single youtube call
+func YoutubeVideoEngagement(clientKey string, videoId string) (*domain.YoutubeCachedEngagement, error) {
+	if _, err := getClient(clientKey); err != nil {
+		return nil, err
 	}
 
-	//- priority to show on redis rather than call to youtube api for 24 hours
-	//- call to redis to get video engagement
-	isVideoEngagementExist, isExpireSoon, err := redis.IsYoutubeVideoEngagementExist(clientKey, videoId)
+	cached, err := redis.GetVideoEngagementInfo(clientKey, videoId)
 	if err != nil {
-		handleError(err, "Error when call IsYoutubeVideoEngagementExist", "error")
 		return nil, err
 	}
-	fmt.Printf("isVideoEngagementExist %v\n", isVideoEngagementExist)
-	if isVideoEngagementExist && !isExpireSoon {
-		videoEngagement, _ := redis.GetVideoEngagementInfo(clientKey, videoId)
-		return videoEngagement, nil
+	if cached != nil {
+		if cached.Stale(time.Now()) {
+			revalidateVideoEngagement(clientKey, videoId)
+		}
+		return cached, nil
 	}
 
-	//- if not exist => call to youtube api to get video engagement
-	call := service.Videos.List(parts)
-	call = call.Id(videoId)
-	response, err := call.Do()
+	result, err, _ := engagementRequests.Do(engagementRequestKey(clientKey, videoId), func() (interface{}, error) {
+		return fetchVideoEngagement(clientKey, videoId)
+	})
 	if err != nil {
-		handleError(err, "Error when call service.Channels.List()", "error")
 		return nil, err
 	}
+	return result.(*domain.YoutubeCachedEngagement), nil
+}
 
-	//- update or cache video engagement to redis
-	_, err = redis.SaveVideoEngagementInfo(clientKey, videoId, response.Items[0].Statistics)
-	if err != nil {
-		handleError(err, "Error when save video engagement information", "error")
-		return nil, err
-	}
+// - EngagementMaxAge is how long engagement read on demand is served without asking youtube again
+func EngagementMaxAge() time.Duration {
+	return utils.DurationOrDefault("YOUTUBE.ENGAGEMENT_MAX_AGE", defaultEngagementMaxAge)
+}
 
-	return response.Items[0].Statistics, nil
+func engagementRequestKey(clientKey string, videoId string) string {
+	return fmt.Sprintf("%s_%s", clientKey, videoId)
 }
 
-func ChannelsListByUsername(service *youtube.Service, part string, forUsername string) {
-	var parts []string
-	parts = append(parts, part)
-	call := service.Channels.List(parts)
-	call = call.ForUsername(forUsername)
-	response, err := call.Do()
+// - EngagementRefreshBackoff is how long stale engagement is served as is after its refresh failed
+func EngagementRefreshBackoff() time.Duration {
+	return utils.DurationOrDefault("YOUTUBE.ENGAGEMENT_REFRESH_BACKOFF", defaultEngagementRefreshBackoff)
+}
+
+// - revalidateVideoEngagement refreshes the cache in the background, at most one refresh per video runs at a time.
+// - A failed refresh is not tried again before the backoff passed, so a spent quota is not hit on every read.
+func revalidateVideoEngagement(clientKey string, videoId string) {
+	backedOff, err := redis.EngagementRefreshBackedOff(clientKey, videoId)
+	if err != nil || backedOff {
+		return
+	}
+
+	engagementRequests.DoChan(engagementRequestKey(clientKey, videoId), func() (interface{}, error) {
+		cached, err := fetchVideoEngagement(clientKey, videoId)
+		if err != nil {
+			handleError(err, fmt.Sprintf("Unable to refresh engagement of youtube video %s", videoId), "error")
+			redis.BackOffEngagementRefresh(clientKey, videoId, EngagementRefreshBackoff())
+		}
+		return cached, err
+	})
+}
 
+func fetchVideoEngagement(clientKey string, videoId string) (*domain.YoutubeCachedEngagement, error) {
+	service, err := BuildServiceFromToken(clientKey)
 	if err != nil {
-		handleError(err, "Error when call service.Channels.List()", "error")
+		return nil, err
+	}
+	parts := []string{
+		"id",
+		"statistics",
 	}
 
-	log.Println(fmt.Sprintf("This channel's ID is %s. Its title is '%s', "+
-		"and it has %d views.",
-		response.Items[0].Id,
-		response.Items[0].Snippet.Title,
-		response.Items[0].Statistics.ViewCount))
-}
+	response, err := service.Videos.List(parts).Id(videoId).Do()
+	if err != nil {
+		handleError(err, "Error when call service.Videos.List()", "error")
+		return nil, err
+	}
+	if len(response.Items) == 0 {
+		return nil, ErrVideoNotFound
+	}
 
-// Retrieve playlistItems in the specified playlist
-func PlaylistItemsList(service *youtube.Service, part string, playlistId string, pageToken string) *youtube.PlaylistItemListResponse {
-	var parts []string
-	parts = append(parts, part)
-	call := service.PlaylistItems.List(parts)
+	return cacheVideoEngagement(clientKey, videoId, response.Items[0].Statistics, EngagementMaxAge(), redis.ENGAGEMENT_EXPIRATION)
+}
 
-	call = call.PlaylistId(playlistId)
-	if pageToken != "" {
-		call = call.PageToken(pageToken)
+// - cacheVideoEngagement keeps the statistics read from youtube, fresh for maxAge and in redis until expiration,
+// - and adds them to the history
+func cacheVideoEngagement(clientKey string, videoId string, statistics *youtube.VideoStatistics, maxAge time.Duration, expiration time.Duration) (*domain.YoutubeCachedEngagement, error) {
+	now := time.Now()
+	cached := &domain.YoutubeCachedEngagement{
+		Statistics: statistics,
+		FetchedAt:  now,
+		MaxAge:     domain.Duration(maxAge),
+	}
+	_, err := redis.SaveVideoEngagementInfo(clientKey, videoId, cached, expiration)
+	if err != nil {
+		handleError(err, "Error when save video engagement information", "error")
+		return nil, err
 	}
-	response, err := call.Do()
-	handleError(err, "Cannot get playlistItemsList", "error")
-	return response
-}
 
-// Retrieve resource for the authenticated user's channel
-func ChannelsListMine(service *youtube.Service, part string) *youtube.ChannelListResponse {
-	var parts []string
-	parts = append(parts, part)
-	call := service.Channels.List(parts)
-	call = call.Mine(true)
-	response, err := call.Do()
-	handleError(err, "Cannot get channelsListMine", "error")
-	return response
+	snapshot := domain.NewYoutubeEngagementSnapshot(statistics, now)
+	if _, err = redis.SaveEngagementSnapshot(clientKey, videoId, snapshot); err != nil {
+		handleError(err, "Error when save video engagement snapshot", "error")
+	}
+	return cached, nil
 }